@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/everfir/logger-go/internal/tracer"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// W3C Baggage 规范中的大小限制
+const (
+	maxBaggageMembers     = 180
+	maxBaggageMemberBytes = 4096
+	maxBaggageStringBytes = 8192
+)
+
+// SetBaggage 设置baggage中的key/value，返回携带新baggage的上下文
+// value 无需预先编码，传播时会按规范进行百分号编码
+func SetBaggage(ctx context.Context, key, value string) (context.Context, error) {
+	if !validBaggageKey(key) {
+		return ctx, fmt.Errorf("invalid baggage key: %q", key)
+	}
+
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("invalid baggage member %q: %w", key, err)
+	}
+	if n := len(member.String()); n > maxBaggageMemberBytes {
+		return ctx, fmt.Errorf("baggage member %q too large: %d > %d bytes", key, n, maxBaggageMemberBytes)
+	}
+
+	bag, err := tracer.BaggageFromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, fmt.Errorf("set baggage member %q failed: %w", key, err)
+	}
+	if n := bag.Len(); n > maxBaggageMembers {
+		return ctx, fmt.Errorf("too many baggage members: %d > %d", n, maxBaggageMembers)
+	}
+	if n := len(bag.String()); n > maxBaggageStringBytes {
+		return ctx, fmt.Errorf("baggage too large: %d > %d bytes", n, maxBaggageStringBytes)
+	}
+
+	return tracer.ContextWithBaggage(ctx, bag), nil
+}
+
+// GetBaggage 获取baggage中key对应的value，不存在时返回空字符串
+func GetBaggage(ctx context.Context, key string) string {
+	return tracer.BaggageFromContext(ctx).Member(key).Value()
+}
+
+// RemoveBaggage 删除baggage中的key，返回新的上下文
+func RemoveBaggage(ctx context.Context, key string) context.Context {
+	bag := tracer.BaggageFromContext(ctx)
+	if bag.Member(key).Key() == "" {
+		return ctx
+	}
+	return tracer.ContextWithBaggage(ctx, bag.DeleteMember(key))
+}
+
+// validBaggageKey 校验key是否为RFC7230中定义的token
+func validBaggageKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, c := range []byte(key) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '!', c == '#', c == '$', c == '%', c == '&', c == '\'', c == '*',
+			c == '+', c == '-', c == '.', c == '^', c == '_', c == '`', c == '|', c == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
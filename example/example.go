@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/httpmw"
 	"github.com/everfir/logger-go/structs/field"
 	"github.com/everfir/logger-go/structs/log_level"
 	"github.com/gin-gonic/gin"
@@ -51,11 +52,15 @@ func serverHandler(c *gin.Context) {
 	logger.Info(c.Request.Context(), "服务端测试")
 
 	req, _ := http.NewRequest("GET", "http://localhost:10083", nil)
-	logger.Inject(c, propagation.HeaderCarrier(req.Header), nil)
+	logger.Inject(c, propagation.HeaderCarrier(req.Header))
 	logger.Error(c.Request.Context(), "服务端测试发送请求", field.String("headers", fmt.Sprintf("%v", req.Header)))
 
 	m := map[string]string{}
-	logger.Inject(c, propagation.MapCarrier(m), map[string]string{"new_openid": "new_openid"})
+	ctx, err := logger.SetBaggage(c.Request.Context(), "new_openid", "new_openid")
+	if err != nil {
+		logger.Warn(c, "设置baggage失败", field.Any("error", err))
+	}
+	logger.Inject(ctx, propagation.MapCarrier(m))
 	logger.Error(c.Request.Context(), "服务端测试发送请求", field.Any("map", m))
 
 	// 响应客户端
@@ -70,14 +75,18 @@ func sendRequest(ctx context.Context) error {
 		return fmt.Errorf("创建请求失败: %v", err)
 	}
 
-	// 注入 trace 信息到 header
-	logger.Inject(ctx, propagation.HeaderCarrier(req.Header), map[string]string{"openid": "_openid"})
-
-	// 记录发送的 header
-	logger.Info(ctx, "客户端发送的 header", field.String("headers", fmt.Sprintf("%v", req.Header)))
+	// 设置需要透传的 baggage
+	ctx, err = logger.SetBaggage(ctx, "openid", "_openid")
+	if err != nil {
+		return fmt.Errorf("设置baggage失败: %v", err)
+	}
+	req = req.WithContext(ctx)
 
-	// 发送请求
-	client := &http.Client{}
+	// 发送请求，Transport 注入 trace 信息及 baggage 并记录请求日志，失败时重试一次
+	client := &http.Client{Transport: httpmw.Transport(nil,
+		httpmw.WithRetries(1, 100*time.Millisecond),
+		httpmw.WithSlowThreshold(time.Second),
+	)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("发送请求失败: %v", err)
@@ -108,7 +117,7 @@ func main() {
 	if err != nil {
 		panic(fmt.Sprintf("初始化日志库失败: %v", err))
 	}
-	defer logger.Close()
+	defer logger.Close(context.Background())
 
 	// 创建一个根 span
 	ctx := context.TODO()
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/everfir/logger-go/structs/field"
+)
+
+// fieldsKey 上下文中日志字段的key
+type fieldsKey struct{}
+
+// ContextWithFields 返回携带日志字段的上下文，使用该上下文写入的日志都会包含这些字段，可多次调用追加
+func ContextWithFields(ctx context.Context, fields ...field.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	prev := FieldsFromContext(ctx)
+	return context.WithValue(ctx, fieldsKey{}, append(prev[:len(prev):len(prev)], fields...))
+}
+
+// FieldsFromContext 返回上下文中通过 ContextWithFields 添加的日志字段
+func FieldsFromContext(ctx context.Context) []field.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]field.Field)
+	return fields
+}
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/everfir/logger-go/internal/detector"
+	"github.com/everfir/logger-go/internal/logger"
+	"github.com/everfir/logger-go/internal/redactor"
+	"github.com/everfir/logger-go/internal/tracer"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_config"
+)
+
+type myLogger struct {
+	logger.Logger
+	tracer.Tracer
+
+	config   *log_config.LogConfig
+	resource []field.Field // 服务、主机、进程、容器等资源信息
+	redactor *redactor.Redactor
+
+	// 写日志时持有读锁，退役时持有写锁，保证旧的输出在写入完成后才被关闭
+	mu     sync.RWMutex
+	closed bool
+}
+
+var (
+	// globalLogger 全局日志器，替换时整体原子替换
+	globalLogger = func() *atomic.Pointer[myLogger] {
+		p := new(atomic.Pointer[myLogger])
+		p.Store(fallbackLogger())
+		return p
+	}()
+
+	// initMu 串行化全局日志器的替换
+	initMu sync.Mutex
+)
+
+// closeTimeout 替换日志器时关闭旧 TracerProvider 的超时时间
+const closeTimeout = 20 * time.Second
+
+// fallbackLogger 未初始化或关闭后使用的日志器，JSON 编码输出到标准错误
+func fallbackLogger() *myLogger {
+	return &myLogger{
+		Logger: logger.NewStderrLogger(),
+		Tracer: &tracer.NoTracer{},
+		config: &log_config.DefaultConfig,
+	}
+}
+
+// acquire 获取当前的全局日志器并持有读锁，使用完毕后需调用 release
+// 持有期间不能再次调用 acquire，否则替换日志器时可能死锁
+func acquire() *myLogger {
+	for {
+		l := globalLogger.Load()
+		l.mu.RLock()
+		if !l.closed {
+			return l
+		}
+		// 已被替换并关闭，重新获取
+		l.mu.RUnlock()
+	}
+}
+
+func (l *myLogger) release() {
+	l.mu.RUnlock()
+}
+
+// replace 使用新的配置原子替换全局日志器，返回被替换的配置
+// 导出相关配置未变化时复用原有的tracer，否则创建新的tracer并关闭旧的
+// 可以并发调用，但不能在写日志的过程中（如 ContextHandler 内）调用
+func replace(config *log_config.LogConfig) (*log_config.LogConfig, error) {
+	rdr, err := redactor.New(config.RedactConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	loger, err := newLogger(config)
+	if err != nil {
+		return nil, err
+	}
+
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	prev := globalLogger.Load()
+	tcer, reused := tracer.WithConfig(prev.Tracer, config.TracerConfig, config.Level)
+	if !reused {
+		if tcer, err = newTracer(config); err != nil {
+			_ = loger.Close()
+			return nil, err
+		}
+	}
+
+	globalLogger.Store(&myLogger{
+		Logger:   loger,
+		Tracer:   tcer,
+		config:   config,
+		resource: resourceFields(detector.Detect(config.ResourceServiceName())),
+		redactor: rdr,
+	})
+	if config.FlushOnSignal {
+		startSignalHandler()
+	} else {
+		stopSignalHandler()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	if err = prev.retire(ctx, !reused); err != nil {
+		Warn(context.TODO(), "[Logger] close previous logger failed", field.Any("error", err))
+	}
+	return prev.config, nil
+}
+
+// Swap 使用选项创建新的全局日志器，返回恢复原日志器的函数，配置优先级与 Init 相同
+// 与 Init 不同，原日志器在恢复前不会被关闭，恢复时关闭新的日志器；用于测试等需要临时替换全局日志器的场景
+func Swap(options ...Option) (restore func(), err error) {
+	config := log_config.DefaultConfig.Clone()
+	applyEnv(config)
+	if err = applyOptions(config, options...); err != nil {
+		return nil, fmt.Errorf("[Logger] Swap failed: %w", err)
+	}
+
+	rdr, err := redactor.New(config.RedactConfig)
+	if err != nil {
+		return nil, fmt.Errorf("[Logger] Swap failed: %w", err)
+	}
+	loger, err := newLogger(config)
+	if err != nil {
+		return nil, fmt.Errorf("[Logger] Swap failed: %w", err)
+	}
+	tcer, err := newTracer(config)
+	if err != nil {
+		_ = loger.Close()
+		return nil, fmt.Errorf("[Logger] Swap failed: %w", err)
+	}
+
+	initMu.Lock()
+	prev := globalLogger.Swap(&myLogger{
+		Logger:   loger,
+		Tracer:   tcer,
+		config:   config,
+		resource: resourceFields(detector.Detect(config.ResourceServiceName())),
+		redactor: rdr,
+	})
+	initMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			initMu.Lock()
+			cur := globalLogger.Swap(prev)
+			initMu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+			defer cancel()
+			_ = cur.retire(ctx, true)
+		})
+	}, nil
+}
+
+// retire 等待正在进行的写入完成后关闭输出，tracer 被新日志器复用时不关闭
+// 输出的关闭（如发送 HTTP、Kafka 中剩余的日志）不接收ctx，ctx 结束时不再等待，在后台继续关闭
+func (l *myLogger) retire(ctx context.Context, closeTracer bool) error {
+	done := make(chan error, 1)
+	go func() {
+		l.mu.Lock()
+		l.closed = true
+		l.mu.Unlock()
+		done <- l.Logger.Close()
+	}()
+
+	var errs []error
+	select {
+	case err := <-done:
+		errs = append(errs, err)
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("close outputs: %w", ctx.Err()))
+	}
+	if closeTracer && l.Tracer != nil {
+		if err := l.Tracer.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
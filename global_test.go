@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// TestConcurrentReinit 在 -race 下运行：写日志的同时重复 Init、热加载及 Close
+func TestConcurrentReinit(t *testing.T) {
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+
+	// 文件输出写入工作目录下的 ./log
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	path := filepath.Join(dir, "logger.json")
+	writeConfig := func(level string) {
+		data := fmt.Sprintf(`{"level":%q,"output_files":["app.log"],"error_files":["error.log"],"watch_interval":"10ms"}`, level)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Error(err)
+		}
+	}
+	writeConfig("info")
+	if err = InitFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Close(context.Background()) })
+
+	const writers = 8
+	var (
+		wg      sync.WaitGroup
+		stop    atomic.Bool
+		written atomic.Int64
+	)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := ContextWithFields(context.Background(), field.Int("writer", i))
+			for n := 0; !stop.Load(); n++ {
+				Info(ctx, "hammer", field.Int("n", n))
+				Error(ctx, "hammer error", field.Int("n", n))
+				if n%64 == 0 {
+					_ = Sync()
+					_ = OutputStats()
+				}
+				written.Add(1)
+			}
+		}(i)
+	}
+
+	levels := []log_level.Level{log_level.DebugLevel, log_level.InfoLevel, log_level.WarnLevel}
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		switch i % 4 {
+		case 0:
+			if err := Init(WithLevel(levels[i%len(levels)]), WithOutputFiles("app.log")); err != nil {
+				t.Error(err)
+			}
+		case 1:
+			writeConfig(levels[i%len(levels)].String())
+			if err := InitFromFile(path); err != nil {
+				t.Error(err)
+			}
+		case 2:
+			// 触发热加载，同时直接替换配置
+			writeConfig(levels[i%len(levels)].String())
+			config := log_config.DefaultConfig.Clone()
+			config.Level = levels[i%len(levels)]
+			config.OutputFiles = []string{"app.log"}
+			config.FixDefault()
+			if _, err := reload(config); err != nil {
+				t.Error(err)
+			}
+		case 3:
+			if err := Close(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stop.Store(true)
+	wg.Wait()
+	if written.Load() == 0 {
+		t.Fatal("no logs written")
+	}
+}
+
+// TestFileConfigPrecedence 配置文件 < LOGGER_* 环境变量 < Option
+func TestFileConfigPrecedence(t *testing.T) {
+	data := []byte("level: debug\nservice_name: from-file\nmax_backups: 3\n")
+
+	t.Setenv("LOGGER_LEVEL", "warn")
+	t.Setenv("LOGGER_SERVICE_NAME", "from-env")
+	config, err := fileConfig("logger.yaml", data, WithLevel(log_level.ErrorLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Level != log_level.ErrorLevel || config.ServiceName != "from-env" || config.MaxBackups != 3 {
+		t.Errorf("got level=%s service_name=%s max_backups=%d", config.Level, config.ServiceName, config.MaxBackups)
+	}
+
+	t.Setenv("LOGGER_LEVEL", "verbose")
+	if _, err = fileConfig("logger.yaml", data); err == nil || !strings.Contains(err.Error(), "LOGGER_LEVEL") {
+		t.Errorf("invalid env: got %v", err)
+	}
+}
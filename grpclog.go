@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"google.golang.org/grpc/grpclog"
+)
+
+// grpcLogger 将 gRPC 的日志写入全局日志器
+type grpcLogger struct {
+	verbosity int
+}
+
+// NewGRPCLogger 返回写入全局日志器的 grpclog.LoggerV2，需在使用 gRPC 前调用 grpclog.SetLoggerV2(logger.NewGRPCLogger(0))
+// Info、Warning、Error、Fatal 分别对应 info、warn、error、fatal 级别；verbosity 为允许的最大 V 级别，
+// V(0) 需要开启 info 级别，V(1) 及以上需要开启 debug 级别
+func NewGRPCLogger(verbosity int) grpclog.LoggerV2 {
+	return &grpcLogger{verbosity: verbosity}
+}
+
+func (g *grpcLogger) Info(args ...any) { g.log(log_level.InfoLevel, 1, fmt.Sprint(args...)) }
+func (g *grpcLogger) Infoln(args ...any) {
+	g.log(log_level.InfoLevel, 1, sprintln(args...))
+}
+func (g *grpcLogger) Infof(format string, args ...any) {
+	g.log(log_level.InfoLevel, 1, fmt.Sprintf(format, args...))
+}
+func (g *grpcLogger) InfoDepth(depth int, args ...any) {
+	g.log(log_level.InfoLevel, depth+2, sprintln(args...))
+}
+
+func (g *grpcLogger) Warning(args ...any) { g.log(log_level.WarnLevel, 1, fmt.Sprint(args...)) }
+func (g *grpcLogger) Warningln(args ...any) {
+	g.log(log_level.WarnLevel, 1, sprintln(args...))
+}
+func (g *grpcLogger) Warningf(format string, args ...any) {
+	g.log(log_level.WarnLevel, 1, fmt.Sprintf(format, args...))
+}
+func (g *grpcLogger) WarningDepth(depth int, args ...any) {
+	g.log(log_level.WarnLevel, depth+2, sprintln(args...))
+}
+
+func (g *grpcLogger) Error(args ...any) { g.log(log_level.ErrorLevel, 1, fmt.Sprint(args...)) }
+func (g *grpcLogger) Errorln(args ...any) {
+	g.log(log_level.ErrorLevel, 1, sprintln(args...))
+}
+func (g *grpcLogger) Errorf(format string, args ...any) {
+	g.log(log_level.ErrorLevel, 1, fmt.Sprintf(format, args...))
+}
+func (g *grpcLogger) ErrorDepth(depth int, args ...any) {
+	g.log(log_level.ErrorLevel, depth+2, sprintln(args...))
+}
+
+func (g *grpcLogger) Fatal(args ...any) { g.log(log_level.FatalLevel, 1, fmt.Sprint(args...)) }
+func (g *grpcLogger) Fatalln(args ...any) {
+	g.log(log_level.FatalLevel, 1, sprintln(args...))
+}
+func (g *grpcLogger) Fatalf(format string, args ...any) {
+	g.log(log_level.FatalLevel, 1, fmt.Sprintf(format, args...))
+}
+func (g *grpcLogger) FatalDepth(depth int, args ...any) {
+	g.log(log_level.FatalLevel, depth+2, sprintln(args...))
+}
+
+// V 判断 gRPC 的详细级别是否开启
+func (g *grpcLogger) V(l int) bool {
+	if l > g.verbosity {
+		return false
+	}
+
+	level := log_level.InfoLevel
+	if l > 0 {
+		level = log_level.DebugLevel
+	}
+
+	lg := acquire()
+	defer lg.release()
+	return level >= lg.config.Level
+}
+
+// log 写入日志，depth 为需要跳过的调用层数，FATAL 级别写入后退出进程
+// gRPC 通过内部的 grpclog.XxxDepth 调用 XxxDepth 方法，depth 从其调用方开始计算，因此 XxxDepth 需多跳过一层；
+// 未实现 XxxDepth 时 gRPC 使用 Xxxln，因此 XxxDepth 与 Xxxln 的格式相同；
+// grpclog.Info 等包级函数直接调用 Info 等方法，调用位置跳过 grpclog 包中的函数
+func (g *grpcLogger) log(level log_level.Level, depth int, msg string) {
+	var pcs [8]uintptr
+	// 跳过 runtime.Callers 及 log
+	n := runtime.Callers(depth+2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	frame, more := frames.Next()
+	for more && strings.HasPrefix(frame.Function, "google.golang.org/grpc/grpclog.") {
+		frame, more = frames.Next()
+	}
+	write(context.Background(), level, true, &frame, msg)
+}
+
+func sprintln(args ...any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
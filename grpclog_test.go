@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"io"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"google.golang.org/grpc/grpclog"
+)
+
+func setGRPCLogger(t *testing.T, verbosity int) {
+	t.Helper()
+	grpclog.SetLoggerV2(NewGRPCLogger(verbosity))
+	t.Cleanup(func() { grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, io.Discard, io.Discard)) })
+}
+
+func TestGRPCLogger(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithLevel(log_level.InfoLevel))
+	setGRPCLogger(t, 2)
+
+	grpclog.Info("a", 1)
+	grpclog.Infof("b %d", 2)
+	grpclog.Warningln("c", 3)
+	grpclog.Errorf("d")
+	grpclog.Component("test").InfoDepth(0, "e")
+	NewGRPCLogger(0).Info("direct")
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ msg, level string }{
+		{"a1", "INFO"}, {"b 2", "INFO"}, {"c 3", "WARN"}, {"d", "ERROR"}, {"[test] e", "INFO"}, {"direct", "INFO"},
+	}
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	for i, w := range want {
+		if entries[i]["msg"] != w.msg || entries[i]["level"] != w.level {
+			t.Errorf("entry %d: got %v, want %v", i, entries[i], w)
+		}
+		checkCaller(t, entries[i], "grpclog_test.go")
+	}
+}
+
+func TestGRPCLoggerV(t *testing.T) {
+	tests := []struct {
+		level     log_level.Level
+		verbosity int
+		v         int
+		want      bool
+	}{
+		{log_level.InfoLevel, 0, 0, true},
+		{log_level.InfoLevel, 2, 1, false},
+		{log_level.WarnLevel, 2, 0, false},
+		{log_level.DebugLevel, 2, 2, true},
+		{log_level.DebugLevel, 2, 3, false},
+	}
+	for _, tt := range tests {
+		swapForTest(t, WithLevel(tt.level))
+		if got := NewGRPCLogger(tt.verbosity).V(tt.v); got != tt.want {
+			t.Errorf("level %s verbosity %d: V(%d) = %v, want %v", tt.level, tt.verbosity, tt.v, got, tt.want)
+		}
+	}
+}
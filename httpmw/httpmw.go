@@ -0,0 +1,304 @@
+// Package httpmw 提供 net/http 中间件：提取trace信息、创建服务端span并记录访问日志
+package httpmw
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware 返回记录访问日志及创建服务端span的 http.Handler
+// 请求上下文中会带上 method、path、client_ip 字段，处理函数中使用 r.Context() 写入的日志都会包含这些字段
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	c := newConfig(opts...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.skip(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		serve(c, next, w, r)
+	})
+}
+
+// serve 处理单个请求
+func serve(c *config, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	clientIP := c.clientIP(r)
+
+	ctx := logger.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := logger.Start(ctx, r.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(requestAttributes(r, clientIP)...),
+	)
+	if span == nil {
+		span = trace.SpanFromContext(ctx)
+	}
+	ctx = logger.ContextWithFields(ctx,
+		field.String("method", r.Method),
+		field.String("path", r.URL.Path),
+		field.String("client_ip", clientIP),
+	)
+
+	rw := &responseWriter{ResponseWriter: w}
+	r = r.WithContext(ctx)
+
+	defer func() {
+		// 记录panic后继续向上抛出，交由 http.Server 处理
+		rec := recover()
+		if rec != nil {
+			rw.status = http.StatusInternalServerError
+		}
+		finish(ctx, c, span, r, rw, time.Since(start), rec)
+		if rec != nil {
+			panic(rec)
+		}
+	}()
+
+	next.ServeHTTP(rw, r)
+}
+
+// finish 结束span并记录访问日志
+func finish(ctx context.Context, c *config, span trace.Span, r *http.Request, rw *responseWriter, latency time.Duration, rec any) {
+	status := rw.Status()
+	route := c.routeFunc(r)
+
+	if route != "" {
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+	span.SetAttributes(
+		semconv.HTTPResponseStatusCode(status),
+		semconv.HTTPResponseBodySize(int(rw.bytes)),
+	)
+
+	fields := []field.Field{
+		field.String("route", route),
+		field.Int("status", status),
+		field.Int64("bytes", rw.bytes),
+		field.Duration("latency", latency),
+		field.String("user_agent", r.UserAgent()),
+	}
+
+	level := c.level
+	if c.slowThreshold > 0 && latency > c.slowThreshold {
+		level = max(level, log_level.WarnLevel)
+		fields = append(fields, field.Bool("slow", true))
+	}
+	if rec != nil {
+		err, ok := rec.(error)
+		if !ok {
+			err = fmt.Errorf("%v", rec)
+		}
+		span.RecordError(err, trace.WithStackTrace(true))
+		fields = append(fields, field.String("panic", err.Error()))
+	}
+	if status >= http.StatusInternalServerError {
+		level = c.errorLevel
+		span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+
+	write(ctx, level, "[HTTP] access", fields...)
+}
+
+// write 按级别写入日志，不会以 fatal 级别退出进程
+func write(ctx context.Context, level log_level.Level, msg string, fields ...field.Field) {
+	switch level {
+	case log_level.DebugLevel:
+		logger.Debug(ctx, msg, fields...)
+	case log_level.InfoLevel:
+		logger.Info(ctx, msg, fields...)
+	case log_level.WarnLevel:
+		logger.Warn(ctx, msg, fields...)
+	default:
+		logger.Error(ctx, msg, fields...)
+	}
+}
+
+// skip 是否跳过该路径
+func (c *config) skip(urlPath string) bool {
+	for _, pattern := range c.skipPaths {
+		if ok, _ := path.Match(pattern, urlPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAttributes 返回请求的语义约定属性
+func requestAttributes(r *http.Request, clientIP string) []attribute.KeyValue {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.URLScheme(scheme),
+		semconv.URLPath(r.URL.Path),
+		semconv.ClientAddress(clientIP),
+		semconv.NetworkProtocolVersion(protocolVersion(r)),
+	}
+	// 与客户端span相同，查询参数的值可能包含token等敏感信息，只保留key
+	if r.URL.RawQuery != "" {
+		attrs = append(attrs, semconv.URLQuery(redactQuery(r.URL.Query())))
+	}
+	if ua := r.UserAgent(); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(ua))
+	}
+
+	host, port := r.Host, ""
+	if h, p, err := net.SplitHostPort(r.Host); err == nil {
+		host, port = h, p
+	}
+	if host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(p))
+	}
+	return attrs
+}
+
+// protocolVersion 返回 HTTP 协议版本，如 1.1、2
+func protocolVersion(r *http.Request) string {
+	if r.ProtoMinor == 0 && r.ProtoMajor > 1 {
+		return strconv.Itoa(r.ProtoMajor)
+	}
+	return strconv.Itoa(r.ProtoMajor) + "." + strconv.Itoa(r.ProtoMinor)
+}
+
+// clientIP 返回客户端IP，默认为连接地址，只有配置了 WithClientIPHeader 或 WithTrustedProxies 时才读取请求头
+func (c *config) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	fromProxy := c.trusted(remote)
+	if c.ipHeader != "" && (len(c.proxies) == 0 || fromProxy) {
+		if ip := lastValue(r.Header.Values(c.ipHeader)); ip != "" {
+			return ip
+		}
+		return remote
+	}
+	if !fromProxy {
+		return remote
+	}
+
+	// 代理追加在末尾，从右向左跳过可信代理
+	xff := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(xff) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(xff[i])
+		if ip == "" {
+			continue
+		}
+		if !c.trusted(ip) {
+			return ip
+		}
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	return remote
+}
+
+// trusted 地址是否属于可信代理
+func (c *config) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range c.proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastValue 返回请求头中最后一个非空的值
+func lastValue(values []string) string {
+	for i := len(values) - 1; i >= 0; i-- {
+		items := strings.Split(values[i], ",")
+		for j := len(items) - 1; j >= 0; j-- {
+			if item := strings.TrimSpace(items[j]); item != "" {
+				return item
+			}
+		}
+	}
+	return ""
+}
+
+// responseWriter 记录响应状态码及写入的字节数
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// Status 返回响应状态码，未显式写入时为 200
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	// 1xx 信息响应之后仍会写入最终的状态码
+	if w.status == 0 && status >= http.StatusOK {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("httpmw: underlying ResponseWriter does not implement http.Hijacker")
+}
+
+// Unwrap 供 http.ResponseController 获取原始的 ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,162 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/loggertest"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "00f067aa0ba902b7"
+)
+
+// newTracingLogs 开启 tracing 并将 span 导出到本地的 collector
+func newTracingLogs(t *testing.T) *loggertest.ObservedLogs {
+	t.Helper()
+	collector := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	t.Cleanup(collector.Close)
+	return loggertest.New(t, logger.WithTracing(true, collector.URL, tracer_config.No))
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+	tests := []struct {
+		name   string
+		opts   []Option
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"default ignores headers", nil, "1.1.1.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6", "X-Real-IP": "6.6.6.6"}, "1.1.1.1"},
+		{"untrusted proxy", []Option{proxies}, "1.1.1.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6"}, "1.1.1.1"},
+		{"trusted proxy", []Option{proxies}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "2.2.2.2, 10.0.0.2"}, "2.2.2.2"},
+		{"spoofed prefix", []Option{proxies}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2"}, "2.2.2.2"},
+		{"trusted proxy real ip", []Option{proxies}, "10.0.0.1:1234", map[string]string{"X-Real-IP": "2.2.2.2"}, "2.2.2.2"},
+		{"trusted proxy without headers", []Option{proxies}, "10.0.0.1:1234", nil, "10.0.0.1"},
+		{"header", []Option{WithClientIPHeader("x-real-ip")}, "1.1.1.1:1234", map[string]string{"X-Real-IP": "2.2.2.2"}, "2.2.2.2"},
+		{"header list", []Option{WithClientIPHeader("X-Forwarded-For")}, "1.1.1.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2"}, "2.2.2.2"},
+		{"header from untrusted proxy", []Option{proxies, WithClientIPHeader("X-Real-IP")}, "1.1.1.1:1234", map[string]string{"X-Real-IP": "6.6.6.6"}, "1.1.1.1"},
+		{"header from trusted proxy", []Option{proxies, WithClientIPHeader("X-Real-IP")}, "10.0.0.1:1234", map[string]string{"X-Real-IP": "2.2.2.2"}, "2.2.2.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for key, value := range tt.header {
+				r.Header.Set(key, value)
+			}
+			if got := newConfig(tt.opts...).clientIP(r); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 处理函数及访问日志中的 span_id 为中间件创建的服务端span，父span为请求中的 traceparent
+func TestMiddlewareServerSpan(t *testing.T) {
+	logs := newTracingLogs(t)
+
+	var server trace.SpanContext
+	var parent trace.SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		server = span.SpanContext()
+		if ro, ok := span.(trace_sdk.ReadOnlySpan); ok {
+			parent = ro.Parent()
+		}
+		logger.Info(r.Context(), "handling")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	r.Header.Set("traceparent", "00-"+remoteTraceID+"-"+remoteSpanID+"-01")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !server.IsValid() || server.SpanID().String() == remoteSpanID {
+		t.Fatalf("no server span created: %v", server.SpanID())
+	}
+	if server.TraceID().String() != remoteTraceID {
+		t.Errorf("trace_id: got %s, want %s", server.TraceID(), remoteTraceID)
+	}
+	if parent.SpanID().String() != remoteSpanID || !parent.IsRemote() {
+		t.Errorf("parent: got %s remote %v, want %s", parent.SpanID(), parent.IsRemote(), remoteSpanID)
+	}
+
+	for _, msg := range []string{"handling", "[HTTP] access"} {
+		logs.AssertLogged(t, log_level.InfoLevel, msg,
+			field.String("trace_id", remoteTraceID),
+			field.String("span_id", server.SpanID().String()),
+		)
+	}
+}
+
+// 请求未携带 traceparent 时创建新的 trace，处理函数与访问日志的trace信息相同
+func TestMiddlewareNewTrace(t *testing.T) {
+	logs := newTracingLogs(t)
+
+	var server trace.SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server = trace.SpanContextFromContext(r.Context())
+		logger.Info(r.Context(), "handling")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !server.IsValid() || server.IsRemote() {
+		t.Fatalf("no root span created: %v", server)
+	}
+	for _, msg := range []string{"handling", "[HTTP] access"} {
+		logs.AssertLogged(t, log_level.InfoLevel, msg,
+			field.String("trace_id", server.TraceID().String()),
+			field.String("span_id", server.SpanID().String()),
+		)
+	}
+}
+
+func TestMiddlewareClientIPField(t *testing.T) {
+	logs := loggertest.New(t)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Info(r.Context(), "handling")
+	}), WithTrustedProxies(netip.MustParsePrefix("192.0.2.0/24")))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-For", "2.2.2.2")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	logs.AssertLogged(t, log_level.InfoLevel, "handling", field.String("client_ip", "2.2.2.2"))
+}
+
+// 服务端span的 url.query 与客户端相同，只保留参数的key
+func TestMiddlewareRedactsQuery(t *testing.T) {
+	newTracingLogs(t)
+
+	var query string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ro, ok := trace.SpanFromContext(r.Context()).(trace_sdk.ReadOnlySpan)
+		if !ok {
+			t.Fatal("server span is not recording")
+		}
+		for _, attr := range ro.Attributes() {
+			if attr.Key == semconv.URLQueryKey {
+				query = attr.Value.AsString()
+			}
+		}
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/login?token=secret&user=alice&token=other", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if query != "token=***&user=***" {
+		t.Errorf("url.query: got %q", query)
+	}
+}
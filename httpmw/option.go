@@ -0,0 +1,147 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// config 中间件配置，其中 skipPaths、slowThreshold 及日志级别同时用于 Transport
+type config struct {
+	skipPaths     []string
+	slowThreshold time.Duration
+	level         log_level.Level
+	errorLevel    log_level.Level
+	routeFunc     func(r *http.Request) string
+	proxies       []netip.Prefix
+	ipHeader      string
+}
+
+// transportConfig Transport 配置
+type transportConfig struct {
+	config
+	retries      int
+	retryBackoff time.Duration
+}
+
+// newConfig 返回应用选项后的中间件配置
+func newConfig(opts ...Option) *config {
+	c := &config{
+		level:      log_level.InfoLevel,
+		errorLevel: log_level.ErrorLevel,
+	}
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+	if c.routeFunc == nil {
+		c.routeFunc = serveMuxRoute
+	}
+	return c
+}
+
+// newTransportConfig 返回应用选项后的 Transport 配置
+func newTransportConfig(opts ...TransportOption) *transportConfig {
+	c := &transportConfig{
+		config: config{
+			level:      log_level.InfoLevel,
+			errorLevel: log_level.ErrorLevel,
+		},
+		retryBackoff: 100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt.applyTransport(c)
+	}
+	return c
+}
+
+// Option 中间件配置选项
+type Option interface {
+	apply(*config)
+}
+
+// TransportOption Transport 配置选项
+type TransportOption interface {
+	applyTransport(*transportConfig)
+}
+
+// SharedOption 可以同时用于 Middleware 及 Transport 的配置选项
+type SharedOption func(*config)
+
+func (o SharedOption) apply(c *config) { o(c) }
+
+func (o SharedOption) applyTransport(c *transportConfig) { o(&c.config) }
+
+// optionFunc 仅用于 Middleware 的配置选项
+type optionFunc func(*config)
+
+func (o optionFunc) apply(c *config) { o(c) }
+
+// transportOptionFunc 仅用于 Transport 的配置选项
+type transportOptionFunc func(*transportConfig)
+
+func (o transportOptionFunc) applyTransport(c *transportConfig) { o(c) }
+
+// WithSkipPaths 设置不记录日志、不创建span的路径，支持 path.Match 通配符，如 /healthz、/debug/*
+func WithSkipPaths(paths ...string) SharedOption {
+	return func(c *config) {
+		c.skipPaths = append(c.skipPaths, paths...)
+	}
+}
+
+// WithSlowThreshold 设置慢请求阈值，耗时超过阈值的请求以 warn 级别记录并标记 slow，0 表示不检查
+func WithSlowThreshold(threshold time.Duration) SharedOption {
+	return func(c *config) {
+		c.slowThreshold = threshold
+	}
+}
+
+// WithLevel 设置正常请求日志的级别，默认 info
+func WithLevel(level log_level.Level) SharedOption {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithErrorLevel 设置 5xx、panic 及发送失败的请求日志的级别，默认 error
+func WithErrorLevel(level log_level.Level) SharedOption {
+	return func(c *config) {
+		c.errorLevel = level
+	}
+}
+
+// WithRouteFunc 设置获取路由模板的函数，在请求处理完成后调用，用于 gin 等第三方路由
+// 默认使用 http.ServeMux 匹配的模式（Go 1.23 及以上）
+func WithRouteFunc(fn func(r *http.Request) string) Option {
+	return optionFunc(func(c *config) {
+		c.routeFunc = fn
+	})
+}
+
+// WithTrustedProxies 设置可信代理的地址段，连接来自可信代理时，从右向左取 X-Forwarded-For 中第一个不属于可信代理的地址作为客户端IP，
+// 没有 X-Forwarded-For 时使用 X-Real-IP；默认不信任任何请求头，客户端IP为连接地址
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return optionFunc(func(c *config) {
+		c.proxies = append(c.proxies, prefixes...)
+	})
+}
+
+// WithClientIPHeader 设置由代理写入客户端IP的请求头，如 X-Real-IP、CF-Connecting-IP，值为列表时取最后一个
+// 同时设置了 WithTrustedProxies 时，仅信任来自可信代理的请求头
+func WithClientIPHeader(header string) Option {
+	return optionFunc(func(c *config) {
+		c.ipHeader = http.CanonicalHeaderKey(header)
+	})
+}
+
+// WithRetries 设置 Transport 的最大重试次数及首次重试的等待时间，之后每次等待时间翻倍，默认不重试
+// 仅重试请求体可以重新读取的幂等请求，重试条件为网络错误或 502、503、504 响应
+func WithRetries(retries int, backoff time.Duration) TransportOption {
+	return transportOptionFunc(func(c *transportConfig) {
+		c.retries = retries
+		if backoff > 0 {
+			c.retryBackoff = backoff
+		}
+	})
+}
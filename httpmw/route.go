@@ -0,0 +1,10 @@
+//go:build !go1.23
+
+package httpmw
+
+import "net/http"
+
+// serveMuxRoute Go 1.23 之前 http.Request 不包含匹配的模式
+func serveMuxRoute(*http.Request) string {
+	return ""
+}
@@ -0,0 +1,17 @@
+//go:build go1.23
+
+package httpmw
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveMuxRoute 返回 http.ServeMux 匹配的模式，去掉方法及主机部分
+func serveMuxRoute(r *http.Request) string {
+	pattern := r.Pattern
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+package httpmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transport 记录请求日志及创建客户端span的 http.RoundTripper
+type transport struct {
+	base   http.RoundTripper
+	config *transportConfig
+}
+
+// Transport 返回注入trace信息及baggage、创建客户端span并记录请求日志的 http.RoundTripper，base 为 nil 时使用 http.DefaultTransport
+// 每次发送（包括重试）创建一个span，每个请求记录一条日志，日志中的URL不包含用户信息，查询参数的值被隐藏
+// 日志字段使用 HTTP 语义约定的名称，避免与中间件添加到上下文中的 method、path 等字段重复
+func Transport(base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, config: newTransportConfig(opts...)}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.config.skip(req.URL.Path) {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+	redacted := redactURL(req.URL)
+
+	var (
+		resp    *http.Response
+		err     error
+		retries int
+	)
+	for {
+		resp, err = t.send(ctx, req, redacted, retries)
+		if retries >= t.config.retries || !t.retryable(req, resp, err) {
+			break
+		}
+
+		wait := t.config.retryBackoff << retries
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if waitErr := sleep(ctx, wait); waitErr != nil {
+			resp, err = nil, waitErr
+			break
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				resp, err = nil, bodyErr
+				break
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+		retries++
+	}
+
+	fields := []field.Field{
+		field.String(string(semconv.HTTPRequestMethodKey), method(req)),
+		field.String(string(semconv.URLFullKey), redacted),
+		field.Duration("latency", time.Since(start)),
+		field.Int("retries", retries),
+	}
+
+	level := t.config.level
+	if resp != nil {
+		fields = append(fields, field.Int(string(semconv.HTTPResponseStatusCodeKey), resp.StatusCode))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			level = t.config.errorLevel
+		}
+	}
+	if err != nil {
+		level = t.config.errorLevel
+		fields = append(fields, field.String("error", err.Error()))
+	}
+	if latency := time.Since(start); t.config.slowThreshold > 0 && latency > t.config.slowThreshold {
+		level = max(level, log_level.WarnLevel)
+		fields = append(fields, field.Bool("slow", true))
+	}
+
+	write(ctx, level, "[HTTP] request", fields...)
+	return resp, err
+}
+
+// send 创建客户端span并发送一次请求
+func (t *transport) send(ctx context.Context, req *http.Request, redacted string, resendCount int) (*http.Response, error) {
+	ctx, span := logger.Start(ctx, method(req),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(clientAttributes(req, redacted, resendCount)...),
+	)
+	if span == nil {
+		span = trace.SpanFromContext(ctx)
+	}
+	defer span.End()
+
+	// RoundTripper 不能修改原请求，复制后注入trace信息
+	out := req.Clone(ctx)
+	logger.Inject(ctx, propagation.HeaderCarrier(out.Header))
+
+	resp, err := t.base.RoundTrip(out)
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(semconv.ErrorTypeKey.String(errorType(err)))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// retryable 是否需要重试：幂等请求在网络错误或 502、503、504 时重试，请求体需要能够重新读取
+func (t *transport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if !idempotent(req) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// idempotent 请求方法是否幂等，带有 Idempotency-Key 请求头的请求也视为幂等
+func idempotent(req *http.Request) bool {
+	switch method(req) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// method 返回请求方法，空表示 GET
+func method(req *http.Request) string {
+	if req.Method == "" {
+		return http.MethodGet
+	}
+	return req.Method
+}
+
+// sleep 等待 d 或上下文结束
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// clientAttributes 返回客户端请求的语义约定属性
+func clientAttributes(req *http.Request, redacted string, resendCount int) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method(req)),
+		semconv.URLFull(redacted),
+	}
+	if host := req.URL.Hostname(); host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if port := urlPort(req.URL); port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	if resendCount > 0 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(resendCount))
+	}
+	return attrs
+}
+
+// urlPort 返回URL中的端口，未指定时使用协议的默认端口
+func urlPort(u *url.URL) int {
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		return port
+	}
+	switch u.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
+
+// redactURL 返回去掉用户信息、隐藏查询参数值的URL
+func redactURL(u *url.URL) string {
+	ret := *u
+	ret.User = nil
+	ret.Fragment, ret.RawFragment = "", ""
+	if ret.RawQuery != "" {
+		ret.RawQuery = redactQuery(ret.Query())
+	}
+	return ret.String()
+}
+
+// redactQuery 按key排序拼接查询参数，值替换为 ***
+func redactQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, url.QueryEscape(key)+"=***")
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "&")
+}
+
+// errorType 返回错误类型，用于 error.type 属性
+func errorType(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	}
+	return fmt.Sprintf("%T", err)
+}
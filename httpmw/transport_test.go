@@ -0,0 +1,139 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/loggertest"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// roundTripFunc 将函数适配为 http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// 下游收到的 traceparent 指向 Transport 创建的客户端span，其父span为调用方的span
+func TestTransportInjectsClientSpan(t *testing.T) {
+	newTracingLogs(t)
+
+	var traceparent string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer downstream.Close()
+
+	var client trace.Span
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		client = trace.SpanFromContext(r.Context())
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	ctx, parent := logger.Start(context.Background(), "caller")
+	defer parent.End()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+	resp, err := (&http.Client{Transport: Transport(base)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	sc := client.SpanContext()
+	if sc.SpanID() == parent.SpanContext().SpanID() {
+		t.Fatal("no client span created")
+	}
+	want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("traceparent: got %s, want %s", traceparent, want)
+	}
+
+	ro, ok := client.(trace_sdk.ReadOnlySpan)
+	if !ok {
+		t.Fatalf("client span is not recording: %T", client)
+	}
+	if ro.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span kind: got %s", ro.SpanKind())
+	}
+	if ro.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("parent: got %s, want %s", ro.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+}
+
+// 在中间件处理的请求中调用时，请求日志的字段不与上下文中的 method 重复
+func TestTransportLogFields(t *testing.T) {
+	logs := loggertest.New(t)
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer downstream.Close()
+	client := &http.Client{Transport: Transport(nil)}
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL+"/users?token=secret", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	logs.AssertLogged(t, log_level.InfoLevel, "[HTTP] request",
+		field.String("method", http.MethodPost),
+		field.String("http.request.method", http.MethodGet),
+		field.String("url.full", downstream.URL+"/users?token=***"),
+		field.Int("http.response.status_code", http.StatusAccepted),
+	)
+
+	entries := logs.FilterMessage("[HTTP] request").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d request entries, want 1", len(entries))
+	}
+	keys := make(map[string]int)
+	for _, f := range entries[0].Fields {
+		keys[f.Key()]++
+	}
+	for key, n := range keys {
+		if n > 1 {
+			t.Errorf("key %s logged %d times", key, n)
+		}
+	}
+}
+
+func TestTransportRetries(t *testing.T) {
+	logs := loggertest.New(t)
+
+	var mu sync.Mutex
+	var hits int
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if hits++; hits == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{Transport: Transport(nil, WithRetries(2, time.Millisecond), WithLevel(log_level.DebugLevel))}
+	resp, err := client.Get(downstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || hits != 2 {
+		t.Errorf("status %d after %d requests", resp.StatusCode, hits)
+	}
+	logs.AssertLogged(t, log_level.DebugLevel, "[HTTP] request", field.Int("retries", 1))
+}
@@ -0,0 +1,135 @@
+package detector
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// 资源属性相关的环境变量
+const (
+	EnvResourceAttributes = "OTEL_RESOURCE_ATTRIBUTES"
+
+	envServiceVersion        = "SERVICE_VERSION"
+	envServiceNamespace      = "SERVICE_NAMESPACE"
+	envDeploymentEnvironment = "DEPLOYMENT_ENVIRONMENT"
+)
+
+// k8s downward API 注入的环境变量，按顺序取第一个非空值
+var (
+	envPodName   = []string{"K8S_POD_NAME", "POD_NAME"}
+	envNamespace = []string{"K8S_NAMESPACE", "POD_NAMESPACE"}
+	envNodeName  = []string{"K8S_NODE_NAME", "NODE_NAME"}
+)
+
+// cgroupPath 用于解析容器ID的cgroup文件
+var cgroupPath = "/proc/self/cgroup"
+
+var containerIDRegexp = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// Detect 探测当前进程所在的服务、主机、进程、容器及k8s信息
+// 优先级从低到高：自动探测 < 环境变量 < OTEL_RESOURCE_ATTRIBUTES < serviceName
+func Detect(serviceName string) []attribute.KeyValue {
+	attrs := make(map[attribute.Key]attribute.Value)
+	set := func(key attribute.Key, value string) {
+		if value != "" {
+			attrs[key] = attribute.StringValue(value)
+		}
+	}
+
+	// 自动探测
+	if host, err := os.Hostname(); err == nil {
+		set(semconv.HostNameKey, host)
+	}
+	attrs[semconv.ProcessPIDKey] = attribute.IntValue(os.Getpid())
+	if exe, err := os.Executable(); err == nil {
+		set(semconv.ProcessExecutablePathKey, exe)
+		set(semconv.ProcessExecutableNameKey, filepath.Base(exe))
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+		set(semconv.ServiceVersionKey, info.Main.Version)
+	}
+	set(semconv.ContainerIDKey, containerID())
+
+	// 环境变量
+	set(semconv.ServiceVersionKey, os.Getenv(envServiceVersion))
+	set(semconv.ServiceNamespaceKey, os.Getenv(envServiceNamespace))
+	set(semconv.DeploymentEnvironmentKey, os.Getenv(envDeploymentEnvironment))
+	set(semconv.K8SPodNameKey, getenv(envPodName...))
+	set(semconv.K8SNamespaceNameKey, getenv(envNamespace...))
+	set(semconv.K8SNodeNameKey, getenv(envNodeName...))
+
+	// OTEL_RESOURCE_ATTRIBUTES
+	for key, value := range ParseResourceAttributes(os.Getenv(EnvResourceAttributes)) {
+		set(attribute.Key(key), value)
+	}
+
+	// 代码中显式指定的服务名
+	set(semconv.ServiceNameKey, serviceName)
+	if _, ok := attrs[semconv.ServiceNameKey]; !ok {
+		set(semconv.ServiceNameKey, "unknown_service:"+filepath.Base(os.Args[0]))
+	}
+
+	ret := make([]attribute.KeyValue, 0, len(attrs))
+	for key, value := range attrs {
+		ret = append(ret, attribute.KeyValue{Key: key, Value: value})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
+
+// ParseResourceAttributes 解析 key1=value1,key2=value2 格式的资源属性，value 支持百分号编码
+// 格式错误的项会被忽略
+func ParseResourceAttributes(s string) map[string]string {
+	ret := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		ret[key] = value
+	}
+	return ret
+}
+
+// containerID 从cgroup信息中解析容器ID，非容器环境返回空字符串
+func containerID() string {
+	f, err := os.Open(cgroupPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ids := containerIDRegexp.FindAllString(scanner.Text(), -1)
+		if len(ids) > 0 {
+			return ids[len(ids)-1]
+		}
+	}
+	return ""
+}
+
+// getenv 返回第一个非空的环境变量值
+func getenv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
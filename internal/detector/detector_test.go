@@ -0,0 +1,95 @@
+package detector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+func detect(serviceName string) map[attribute.Key]string {
+	ret := make(map[attribute.Key]string)
+	for _, attr := range Detect(serviceName) {
+		ret[attr.Key] = attr.Value.Emit()
+	}
+	return ret
+}
+
+func TestDetectPrecedence(t *testing.T) {
+	t.Setenv(envServiceVersion, "1.0.0")
+	t.Setenv(envDeploymentEnvironment, "staging")
+	t.Setenv("POD_NAME", "api-0")
+	t.Setenv("K8S_POD_NAME", "")
+	t.Setenv("NODE_NAME", "node-1")
+	t.Setenv(EnvResourceAttributes, "deployment.environment=prod,service.name=from-attrs,team=a%20b")
+
+	attrs := detect("")
+	tests := map[attribute.Key]string{
+		semconv.ServiceVersionKey:        "1.0.0",
+		semconv.DeploymentEnvironmentKey: "prod", // OTEL_RESOURCE_ATTRIBUTES 优先于环境变量
+		semconv.K8SPodNameKey:            "api-0",
+		semconv.K8SNodeNameKey:           "node-1",
+		semconv.ServiceNameKey:           "from-attrs",
+		"team":                           "a b",
+	}
+	for key, want := range tests {
+		if got := attrs[key]; got != want {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+	if attrs[semconv.ProcessPIDKey] != attribute.IntValue(os.Getpid()).Emit() {
+		t.Errorf("pid: got %q", attrs[semconv.ProcessPIDKey])
+	}
+
+	// 显式指定的服务名优先级最高
+	if got := detect("from-code")[semconv.ServiceNameKey]; got != "from-code" {
+		t.Errorf("service.name: got %q", got)
+	}
+}
+
+func TestDetectUnknownService(t *testing.T) {
+	t.Setenv(EnvResourceAttributes, "")
+	want := "unknown_service:" + filepath.Base(os.Args[0])
+	if got := detect("")[semconv.ServiceNameKey]; got != want {
+		t.Errorf("service.name: got %q, want %q", got, want)
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	id := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		cgroup string
+		want   string
+	}{
+		{"12:pids:/kubepods/besteffort/pod1/" + id + "\n", id},
+		{"0::/system.slice/docker-" + id + ".scope\n", id},
+		{"0::/user.slice/user-1000.slice\n", ""},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "cgroup")
+		if err := os.WriteFile(path, []byte(tt.cgroup), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cgroupPath = path
+		if got := containerID(); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.cgroup, got, tt.want)
+		}
+	}
+
+	cgroupPath = filepath.Join(t.TempDir(), "missing")
+	if got := containerID(); got != "" {
+		t.Errorf("missing cgroup file: got %q", got)
+	}
+	cgroupPath = "/proc/self/cgroup"
+}
+
+func TestParseResourceAttributes(t *testing.T) {
+	got := ParseResourceAttributes(" a = 1 ,b=x%2Cy,=ignored,novalue,c=%zz,d=")
+	want := map[string]string{"a": "1", "b": "x,y", "d": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
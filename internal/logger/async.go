@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/everfir/logger-go/structs/async_config"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultAsyncBufferSize    = 8192
+	defaultAsyncFlushInterval = 100 * time.Millisecond
+)
+
+// asyncCore 在调用方goroutine中编码日志，由 asyncWriter 在后台写入输出
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out *asyncWriter
+}
+
+func newAsyncCore(enc zapcore.Encoder, out *asyncWriter, enab zapcore.LevelEnabler) *asyncCore {
+	return &asyncCore{LevelEnabler: enab, enc: enc, out: out}
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.out.write(ent.Level, buf.Bytes())
+	buf.Free()
+
+	// 与 zap 一致，可能导致进程退出的日志立即写入
+	if ent.Level > zapcore.ErrorLevel {
+		return c.out.Sync()
+	}
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.out.Sync()
+}
+
+// asyncWriter 有界缓冲区，后台goroutine定期或缓冲区过半时批量写入输出
+type asyncWriter struct {
+	out       zapcore.WriteSyncer
+	name      string
+	enc       zapcore.Encoder // 编码丢弃汇总日志
+	policy    async_config.Policy
+	dropLevel zapcore.Level
+
+	mu       sync.Mutex
+	notFull  *sync.Cond
+	buf      [][]byte // 环形缓冲区
+	head     int
+	count    int
+	closed   bool
+	flushing sync.Mutex // 保证批量写入的顺序
+
+	dropped  atomic.Uint64 // 累计丢弃数量
+	reported uint64        // 上次汇总时的丢弃数量
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, name string, enc zapcore.Encoder, config *async_config.AsyncConfig) *asyncWriter {
+	size := config.BufferSize
+	if size <= 0 {
+		size = defaultAsyncBufferSize
+	}
+	interval := config.FlushInterval
+	if interval <= 0 {
+		interval = defaultAsyncFlushInterval
+	}
+	policy := config.Policy
+	if policy == "" {
+		policy = async_config.Block
+	}
+	dropLevel := log_level.WarnLevel
+	if config.DropLevel != nil {
+		dropLevel = *config.DropLevel
+	}
+
+	w := &asyncWriter{
+		out:       out,
+		name:      name,
+		enc:       enc,
+		policy:    policy,
+		dropLevel: dropLevel.ToZapLevel(),
+		buf:       make([][]byte, size),
+		kick:      make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run(interval)
+	return w
+}
+
+// write 复制日志内容放入缓冲区，缓冲区满时按策略处理
+func (w *asyncWriter) write(level zapcore.Level, p []byte) {
+	line := append([]byte(nil), p...)
+
+	w.mu.Lock()
+	for w.count == len(w.buf) && !w.closed {
+		switch {
+		case w.policy == async_config.DropNewest,
+			w.policy == async_config.DropBelowLevel && level < w.dropLevel:
+			w.mu.Unlock()
+			w.dropped.Add(1)
+			return
+		case w.policy == async_config.DropOldest:
+			w.buf[w.head] = nil
+			w.head = (w.head + 1) % len(w.buf)
+			w.count--
+			w.dropped.Add(1)
+		default:
+			w.signal()
+			w.notFull.Wait()
+		}
+	}
+	if w.closed {
+		// 关闭后直接写入，避免丢失关闭过程中的日志
+		w.mu.Unlock()
+		_, _ = w.out.Write(line)
+		return
+	}
+
+	w.buf[(w.head+w.count)%len(w.buf)] = line
+	w.count++
+	if w.count >= len(w.buf)/2 {
+		w.signal()
+	}
+	w.mu.Unlock()
+}
+
+// signal 通知后台goroutine提前写入
+func (w *asyncWriter) signal() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			_ = w.flush()
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+		_ = w.flush()
+	}
+}
+
+// flush 取出缓冲区中的所有日志并批量写入，有新的丢弃时追加一条汇总日志
+func (w *asyncWriter) flush() error {
+	w.flushing.Lock()
+	defer w.flushing.Unlock()
+
+	w.mu.Lock()
+	var batch []byte
+	for i := 0; i < w.count; i++ {
+		idx := (w.head + i) % len(w.buf)
+		batch = append(batch, w.buf[idx]...)
+		w.buf[idx] = nil
+	}
+	w.head, w.count = 0, 0
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	batch = append(batch, w.summary()...)
+	if len(batch) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(batch)
+	return err
+}
+
+// summary 编码上次汇总以来的丢弃数量，没有丢弃时返回 nil
+func (w *asyncWriter) summary() []byte {
+	total := w.dropped.Load()
+	n := total - w.reported
+	if n == 0 {
+		return nil
+	}
+	w.reported = total
+
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Now(),
+		Message: "[Logger] log entries dropped by async writer",
+	}
+	buf, err := w.enc.EncodeEntry(ent, []zapcore.Field{
+		{Key: "output", Type: zapcore.StringType, String: w.name},
+		{Key: "dropped", Type: zapcore.Uint64Type, Integer: int64(n)},
+		{Key: "dropped_total", Type: zapcore.Uint64Type, Integer: int64(total)},
+	})
+	if err != nil {
+		return nil
+	}
+	defer buf.Free()
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+// Stats 返回异步写入的运行状态，底层为网络输出时合并其状态
+func (w *asyncWriter) Stats() output_config.Stats {
+	stats := output_config.Stats{Target: w.name}
+	if s, ok := w.out.(statsReporter); ok {
+		stats = s.Stats()
+	}
+	stats.Dropped += w.dropped.Load()
+	return stats
+}
+
+// Sync 写入缓冲区中的所有日志并刷新输出
+func (w *asyncWriter) Sync() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.out.Sync()
+}
+
+// Close 停止后台goroutine并写入剩余的日志，不关闭底层输出
+func (w *asyncWriter) Close() error {
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		close(w.stop)
+		<-w.done
+	})
+	return w.out.Sync()
+}
@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/async_config"
+	"github.com/everfir/logger-go/structs/log_level"
+	"go.uber.org/zap/zapcore"
+)
+
+// gatedWriter 在 open 之前阻塞所有写入，用于在测试中填满异步缓冲区
+type gatedWriter struct {
+	mu      sync.Mutex
+	data    bytes.Buffer
+	gate    chan struct{}
+	entered chan struct{} // 第一次进入 Write 时关闭
+	once    sync.Once
+	opened  sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.data.Write(p)
+}
+
+func (w *gatedWriter) Sync() error { return nil }
+
+func (w *gatedWriter) open() { w.opened.Do(func() { close(w.gate) }) }
+
+func (w *gatedWriter) lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Split(strings.TrimSuffix(w.data.String(), "\n"), "\n")
+}
+
+func testEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LineEnding: "\n"})
+}
+
+// newFullAsyncWriter 创建容量为2的异步写入，返回时后台写入阻塞在 "a" 上，缓冲区中为 "b"、"c"
+func newFullAsyncWriter(t *testing.T, policy async_config.Policy, dropLevel *log_level.Level) (*asyncWriter, *gatedWriter) {
+	t.Helper()
+	out := newGatedWriter()
+	w := newAsyncWriter(out, "test", testEncoder(), &async_config.AsyncConfig{
+		BufferSize:    2,
+		FlushInterval: time.Hour,
+		Policy:        policy,
+		DropLevel:     dropLevel,
+	})
+	t.Cleanup(func() {
+		out.open()
+		_ = w.Close()
+	})
+
+	w.write(zapcore.InfoLevel, []byte("a\n"))
+	<-out.entered
+	w.write(zapcore.InfoLevel, []byte("b\n"))
+	w.write(zapcore.InfoLevel, []byte("c\n"))
+	return w, out
+}
+
+// blocks 检查 write 是否阻塞，返回等待其完成的函数
+func blocks(t *testing.T, write func()) (wait func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		write()
+	}()
+	select {
+	case <-done:
+		t.Fatal("write did not block on a full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	return func() {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("write still blocked after the buffer drained")
+		}
+	}
+}
+
+// logLines 返回输出中的日志行，丢弃汇总替换为 summary(丢弃数)
+func logLines(t *testing.T, out *gatedWriter) []string {
+	t.Helper()
+	var ret []string
+	for _, line := range out.lines() {
+		if !strings.HasPrefix(line, "{") {
+			ret = append(ret, line)
+			continue
+		}
+		var summary struct {
+			Dropped uint64 `json:"dropped"`
+		}
+		if err := json.Unmarshal([]byte(line), &summary); err != nil {
+			t.Fatalf("bad summary %q: %v", line, err)
+		}
+		ret = append(ret, fmt.Sprintf("summary(%d)", summary.Dropped))
+	}
+	return ret
+}
+
+func TestAsyncPolicies(t *testing.T) {
+	debug := log_level.DebugLevel
+
+	tests := []struct {
+		name      string
+		policy    async_config.Policy
+		dropLevel *log_level.Level
+		level     zapcore.Level
+		block     bool
+		want      []string
+	}{
+		{name: "block", policy: async_config.Block, level: zapcore.DebugLevel, block: true, want: []string{"a", "b", "c", "d"}},
+		{name: "default", level: zapcore.DebugLevel, block: true, want: []string{"a", "b", "c", "d"}},
+		{name: "drop_newest", policy: async_config.DropNewest, level: zapcore.ErrorLevel, want: []string{"a", "b", "c", "summary(1)"}},
+		{name: "drop_oldest", policy: async_config.DropOldest, level: zapcore.DebugLevel, want: []string{"a", "c", "d", "summary(1)"}},
+		{name: "drop_below_level drops info", policy: async_config.DropBelowLevel, level: zapcore.InfoLevel, want: []string{"a", "b", "c", "summary(1)"}},
+		{name: "drop_below_level keeps warn", policy: async_config.DropBelowLevel, level: zapcore.WarnLevel, block: true, want: []string{"a", "b", "c", "d"}},
+		// DropLevel 为 debug 时不丢弃任何日志，而不是当作未设置
+		{name: "drop_below_level debug", policy: async_config.DropBelowLevel, dropLevel: &debug, level: zapcore.DebugLevel, block: true, want: []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, out := newFullAsyncWriter(t, tt.policy, tt.dropLevel)
+
+			write := func() { w.write(tt.level, []byte("d\n")) }
+			if tt.block {
+				wait := blocks(t, write)
+				out.open()
+				wait()
+			} else {
+				write()
+				out.open()
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := logLines(t, out)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsyncDroppedSummary(t *testing.T) {
+	w, out := newFullAsyncWriter(t, async_config.DropNewest, nil)
+	w.write(zapcore.InfoLevel, []byte("d\n"))
+	w.write(zapcore.InfoLevel, []byte("e\n"))
+	out.open()
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := out.lines()
+	var summary struct {
+		Msg          string `json:"msg"`
+		Output       string `json:"output"`
+		Dropped      uint64 `json:"dropped"`
+		DroppedTotal uint64 `json:"dropped_total"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("last line %q: %v", lines[len(lines)-1], err)
+	}
+	if summary.Output != "test" || summary.Dropped != 2 || summary.DroppedTotal != 2 {
+		t.Errorf("summary: %+v", summary)
+	}
+	if got := w.Stats().Dropped; got != 2 {
+		t.Errorf("stats dropped: got %d, want 2", got)
+	}
+
+	// 没有新的丢弃时不再输出汇总
+	n := len(lines)
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(out.lines()); got != n {
+		t.Errorf("summary repeated: %v", out.lines()[n:])
+	}
+}
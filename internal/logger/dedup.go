@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupCore 合并连续相同的日志：级别、消息及字段都相同且与上一条的间隔不超过窗口时不输出，
+// 重复结束或超过窗口后输出一条带 repeated、first_seen、last_seen 的汇总日志
+type dedupCore struct {
+	zapcore.Core
+	*dedupState
+}
+
+// dedupState 所有 With 派生的 core 共享，保证“连续”针对整个日志器
+type dedupState struct {
+	encoder zapcore.Encoder
+	window  time.Duration
+	ignore  map[string]bool // 计算摘要时忽略的字段
+
+	mu       sync.Mutex
+	core     zapcore.Core // 写入上一条日志的 core
+	key      uint64
+	ent      zapcore.Entry
+	fields   []zapcore.Field
+	repeated int
+	lastSeen time.Time
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// dedupIgnoredFields 每个请求都不同的关联字段，不参与重复判断
+var dedupIgnoredFields = []string{"trace_id", "span_id", "trace_flags"}
+
+func newDedupCore(core zapcore.Core, window time.Duration, ignoreFields []string) *dedupCore {
+	ignore := make(map[string]bool, len(dedupIgnoredFields)+len(ignoreFields))
+	for _, key := range append(dedupIgnoredFields[:len(dedupIgnoredFields):len(dedupIgnoredFields)], ignoreFields...) {
+		ignore[key] = true
+	}
+
+	s := &dedupState{
+		encoder: zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder}),
+		window:  window,
+		ignore:  ignore,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return &dedupCore{Core: core, dedupState: s}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), dedupState: c.dedupState}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// 即将退出的日志不合并
+	if ent.Level >= zapcore.DPanicLevel {
+		c.mu.Lock()
+		c.flushLocked()
+		c.key = 0
+		c.mu.Unlock()
+		checkWrite(c.Core, ent, fields)
+		return nil
+	}
+
+	key, ok := c.fingerprint(ent, fields)
+
+	c.mu.Lock()
+	if ok && key == c.key && ent.Time.Sub(c.lastSeen) <= c.window {
+		c.repeated++
+		c.lastSeen = ent.Time
+		c.mu.Unlock()
+		return nil
+	}
+	c.flushLocked()
+	if ok {
+		c.core, c.key, c.ent, c.lastSeen = c.Core, key, ent, ent.Time
+		c.fields = append([]zapcore.Field(nil), fields...)
+	} else {
+		c.key = 0
+	}
+	c.mu.Unlock()
+
+	checkWrite(c.Core, ent, fields)
+	return nil
+}
+
+// fingerprint 计算级别、消息及字段的摘要，不包含时间、调用位置及忽略的字段
+func (s *dedupState) fingerprint(ent zapcore.Entry, fields []zapcore.Field) (uint64, bool) {
+	kept := make([]zapcore.Field, 0, len(fields))
+	for _, f := range fields {
+		if !s.ignore[f.Key] {
+			kept = append(kept, f)
+		}
+	}
+
+	buf, err := s.encoder.EncodeEntry(zapcore.Entry{Level: ent.Level, Message: ent.Message}, kept)
+	if err != nil {
+		return 0, false
+	}
+	defer buf.Free()
+
+	h := fnv.New64a()
+	_, _ = h.Write(buf.Bytes())
+	return h.Sum64(), true
+}
+
+// flushLocked 输出上一条日志的重复汇总，调用时需持有锁
+func (s *dedupState) flushLocked() {
+	if s.repeated == 0 {
+		return
+	}
+
+	ent := s.ent
+	ent.Time = s.lastSeen
+	fields := append(s.fields[:len(s.fields):len(s.fields)],
+		zap.Int("repeated", s.repeated),
+		zap.Time("first_seen", s.ent.Time),
+		zap.Time("last_seen", s.lastSeen),
+	)
+	checkWrite(s.core, ent, fields)
+
+	s.repeated = 0
+	s.key = 0
+}
+
+// run 重复结束超过窗口时间后输出汇总，避免汇总一直等到下一条不同的日志
+func (s *dedupState) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.mu.Lock()
+			s.flushLocked()
+			s.mu.Unlock()
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			if s.repeated > 0 && now.Sub(s.lastSeen) > s.window {
+				s.flushLocked()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close 停止后台检查并输出未完成的汇总
+func (s *dedupState) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+// checkWrite 经过 core 的级别检查后写入，兼容各输出级别不同的 core
+func checkWrite(core zapcore.Core, ent zapcore.Entry, fields []zapcore.Field) {
+	if ce := core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
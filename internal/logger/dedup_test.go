@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupIgnoresCorrelationFields(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := newDedupCore(obs, time.Minute, []string{"request_id"})
+
+	now := time.Now()
+	for i, traceID := range []string{"t1", "t2", "t3"} {
+		ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "dependency down", Time: now.Add(time.Duration(i) * time.Millisecond)}
+		_ = core.Write(ent, []zapcore.Field{
+			zap.String("trace_id", traceID),
+			zap.String("span_id", traceID),
+			zap.String("request_id", traceID),
+			zap.String("dependency", "db"),
+		})
+	}
+	// 不同字段值的日志不合并
+	_ = core.Write(zapcore.Entry{Level: zapcore.ErrorLevel, Message: "dependency down", Time: now.Add(time.Second)},
+		[]zapcore.Field{zap.String("dependency", "cache")})
+	_ = core.Close()
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want first + summary + different: %v", len(entries), entries)
+	}
+	if got := entries[1].ContextMap()["repeated"]; got != int64(2) {
+		t.Errorf("repeated: got %v, want 2", got)
+	}
+	if got := entries[1].ContextMap()["trace_id"]; got != "t1" {
+		t.Errorf("summary trace_id: got %v, want first entry's t1", got)
+	}
+	if got := entries[2].ContextMap()["dependency"]; got != "cache" {
+		t.Errorf("last entry: got %v, want cache", got)
+	}
+}
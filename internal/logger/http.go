@@ -0,0 +1,562 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everfir/logger-go/structs/output_config"
+)
+
+const (
+	defaultHTTPBatchSize     = 1000
+	defaultHTTPBatchBytes    = 1 << 20
+	defaultHTTPFlushInterval = time.Second
+	defaultHTTPTimeout       = 10 * time.Second
+	defaultHTTPRetries       = 3
+	defaultHTTPQueueBytes    = 256 << 20
+
+	httpMaxBackoff = 30 * time.Second
+
+	// httpMaxResponseBytes 读取响应体的上限，_bulk 的响应包含每条日志的结果
+	httpMaxResponseBytes = 16 << 20
+
+	// httpMaxPendingBatches 内存中最多积压的批次数，超过后写入本地队列
+	httpMaxPendingBatches = 10
+)
+
+// isHTTPTarget 判断输出目标是否为 HTTP 批量输出
+func isHTTPTarget(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
+
+// errHTTPNotRetryable 不需要重试的请求错误，如 4xx
+var errHTTPNotRetryable = errors.New("not retryable")
+
+// httpRetryBackoff 首次重试前的等待时间，之后每次翻倍
+var httpRetryBackoff = time.Second
+
+// httpWriter 将日志按条数、大小或时间分批 POST 到 HTTP 接口，
+// 发送失败的批次暂存到本地目录，之后按顺序重发
+type httpWriter struct {
+	target  string
+	url     string
+	config  output_config.HTTPConfig
+	service string
+	client  *http.Client
+
+	mu       sync.Mutex
+	pending  [][]byte // 待发送的日志行
+	size     int
+	sending  sync.Mutex // 保证批次按顺序发送
+	stats    output_config.Stats
+	queueSeq int
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+func newHTTPWriter(target string, config *output_config.HTTPConfig, serviceName string) (*httpWriter, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http target %q: %w", target, err)
+	}
+
+	w := &httpWriter{
+		target:  target,
+		service: serviceName,
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if config != nil {
+		w.config = *config.Clone()
+	}
+	w.fixDefault()
+
+	// URL 中的用户名密码作为 Basic 鉴权
+	if u.User != nil {
+		if w.config.Headers == nil {
+			w.config.Headers = make(map[string]string)
+		}
+		password, _ := u.User.Password()
+		req := http.Request{Header: http.Header{}}
+		req.SetBasicAuth(u.User.Username(), password)
+		w.config.Headers["Authorization"] = req.Header.Get("Authorization")
+		u.User = nil
+	}
+	w.url = u.String()
+	w.stats.Target = u.Redacted()
+	w.client = &http.Client{Timeout: w.config.Timeout}
+
+	if w.config.QueueMaxBytes > 0 {
+		if w.config.QueueDir == "" {
+			dir, err := os.Getwd()
+			if err != nil {
+				return nil, err
+			}
+			name := strings.NewReplacer("/", "_", ":", "_", "?", "_", "&", "_", "=", "_").Replace(u.Host + u.Path)
+			w.config.QueueDir = path.Join(dir, "log", "queue", name)
+		}
+		if err = os.MkdirAll(w.config.QueueDir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("create http queue directory failed: %w", err)
+		}
+		w.stats.SpillBytes = w.queueBytes()
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *httpWriter) fixDefault() {
+	c := &w.config
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultHTTPBatchSize
+	}
+	if c.BatchBytes == 0 {
+		c.BatchBytes = defaultHTTPBatchBytes
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultHTTPFlushInterval
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultHTTPTimeout
+	}
+	switch {
+	case c.Retries == 0:
+		c.Retries = defaultHTTPRetries
+	case c.Retries < 0:
+		c.Retries = 0
+	}
+	if c.Compression == "" {
+		c.Compression = "gzip"
+	}
+	if c.QueueMaxBytes == 0 {
+		c.QueueMaxBytes = defaultHTTPQueueBytes
+	}
+	if c.Index == "" {
+		c.Index = "logs-" + w.service
+		if w.service == "" {
+			c.Index = "logs"
+		}
+	}
+}
+
+// Write 按行加入待发送的批次，积压过多时写入本地队列
+func (w *httpWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, line := range bytes.SplitAfter(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		w.pending = append(w.pending, append([]byte(nil), line...))
+		w.size += len(line)
+	}
+
+	if len(w.pending) >= w.config.BatchSize || w.size >= w.config.BatchBytes {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	if len(w.pending) >= w.config.BatchSize*httpMaxPendingBatches {
+		w.enqueueLocked(w.pending)
+		w.pending, w.size = nil, 0
+	}
+	return len(p), nil
+}
+
+func (w *httpWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.kick:
+		}
+		w.flush(true)
+	}
+}
+
+// flush 先重发本地队列中的批次，再发送待发送的日志，retry 为 false 时每个批次只尝试一次
+func (w *httpWriter) flush(retry bool) {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	if !w.sendQueued(retry) {
+		// 服务仍不可用，新的日志直接进入队列，保证顺序
+		w.mu.Lock()
+		if len(w.pending) > 0 {
+			w.enqueueLocked(w.pending)
+			w.pending, w.size = nil, 0
+		}
+		w.mu.Unlock()
+		return
+	}
+
+	for {
+		batch := w.takeBatch()
+		if len(batch) == 0 {
+			return
+		}
+		if err := w.send(batch, retry); err != nil {
+			w.mu.Lock()
+			if errors.Is(err, errHTTPNotRetryable) {
+				w.stats.Dropped += uint64(len(batch))
+			} else {
+				w.enqueueLocked(batch)
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// takeBatch 取出不超过批次大小的日志
+func (w *httpWriter) takeBatch() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, size := 0, 0
+	for n < len(w.pending) && n < w.config.BatchSize && (n == 0 || size+len(w.pending[n]) <= w.config.BatchBytes) {
+		size += len(w.pending[n])
+		n++
+	}
+	batch := w.pending[:n:n]
+	w.pending = w.pending[n:]
+	w.size -= size
+	return batch
+}
+
+// send 发送一个批次，5xx、429 及网络错误时按指数退避重试
+func (w *httpWriter) send(batch [][]byte, retry bool) error {
+	body, contentType, err := w.encode(batch)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errHTTPNotRetryable, err)
+	}
+
+	attempts := 1
+	if retry {
+		attempts += w.config.Retries
+	}
+
+	backoff := httpRetryBackoff
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-w.stop:
+				return err
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > httpMaxBackoff {
+				backoff = httpMaxBackoff
+			}
+		}
+
+		var wait time.Duration
+		var failed int
+		if failed, wait, err = w.post(body, contentType); err == nil {
+			failed = min(failed, len(batch))
+			w.mu.Lock()
+			w.stats.Written += uint64(len(batch) - failed)
+			w.stats.Dropped += uint64(failed)
+			w.stats.Connected = true
+			w.mu.Unlock()
+			return nil
+		}
+
+		w.mu.Lock()
+		w.stats.Connected = false
+		w.stats.LastError, w.stats.LastErrorTime = err.Error(), time.Now()
+		w.mu.Unlock()
+
+		if errors.Is(err, errHTTPNotRetryable) {
+			return err
+		}
+		if wait > backoff {
+			backoff = wait
+		}
+	}
+	return err
+}
+
+// post 发送一次请求，返回部分失败的条数及服务端要求的重试等待时间
+func (w *httpWriter) post(body []byte, contentType string) (failed int, wait time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %v", errHTTPNotRetryable, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if w.config.Compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, httpMaxResponseBytes))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+		return 0, wait, fmt.Errorf("http status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	case resp.StatusCode >= 300:
+		return 0, 0, fmt.Errorf("%w: http status %d: %s", errHTTPNotRetryable, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	if w.config.Preset == output_config.PresetElasticsearch {
+		failed = w.bulkFailures(respBody)
+	}
+	return failed, 0, nil
+}
+
+// bulkFailures 返回 _bulk 响应中失败的条目数
+// 部分失败时返回 200 及 errors=true，失败的条目不重试，避免重复写入成功的条目
+func (w *httpWriter) bulkFailures(respBody []byte) int {
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if json.Unmarshal(respBody, &result) != nil || !result.Errors {
+		return 0
+	}
+
+	failed := 0
+	var reason json.RawMessage
+	for _, item := range result.Items {
+		for _, res := range item {
+			if len(res.Error) > 0 || res.Status >= 300 {
+				failed++
+				if reason == nil {
+					reason = res.Error
+				}
+			}
+		}
+	}
+
+	w.mu.Lock()
+	w.stats.LastError = fmt.Sprintf("elasticsearch bulk request has %d failed items: %s", failed, reason)
+	w.stats.LastErrorTime = time.Now()
+	w.mu.Unlock()
+	return failed
+}
+
+// encode 按预设格式编码请求体并压缩
+func (w *httpWriter) encode(batch [][]byte) ([]byte, string, error) {
+	var body []byte
+	var contentType string
+	var err error
+	switch w.config.Preset {
+	case output_config.PresetLoki:
+		body, err = w.lokiBody(batch)
+		contentType = "application/json"
+	case output_config.PresetElasticsearch:
+		action := []byte(`{"create":{"_index":` + strconv.Quote(w.config.Index) + "}}\n")
+		for _, line := range batch {
+			body = append(body, action...)
+			body = append(body, line...)
+		}
+		contentType = "application/x-ndjson"
+	default:
+		body = bytes.Join(batch, nil)
+		contentType = "application/x-ndjson"
+	}
+	if err != nil || w.config.Compression != "gzip" {
+		return body, contentType, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err = gz.Write(body); err == nil {
+		err = gz.Close()
+	}
+	return buf.Bytes(), contentType, err
+}
+
+// lokiBody 按 level 分为不同的 stream，时间戳使用日志中的 timestamp
+func (w *httpWriter) lokiBody(batch [][]byte) ([]byte, error) {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+
+	streams := make(map[string]*stream)
+	for _, line := range batch {
+		var entry struct {
+			Level     string `json:"level"`
+			Timestamp string `json:"timestamp"`
+		}
+		_ = json.Unmarshal(line, &entry)
+
+		ts := time.Now()
+		if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+			ts = t
+		}
+		level := strings.ToLower(entry.Level)
+
+		s, ok := streams[level]
+		if !ok {
+			labels := map[string]string{"service_name": w.service}
+			for key, value := range w.config.Labels {
+				labels[key] = value
+			}
+			if level != "" {
+				labels["level"] = level
+			}
+			s = &stream{Stream: labels}
+			streams[level] = s
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), string(bytes.TrimRight(line, "\n"))})
+	}
+
+	levels := make([]string, 0, len(streams))
+	for level := range streams {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+
+	payload := struct {
+		Streams []*stream `json:"streams"`
+	}{}
+	for _, level := range levels {
+		payload.Streams = append(payload.Streams, streams[level])
+	}
+	return json.Marshal(payload)
+}
+
+// enqueueLocked 将批次写入本地队列，超过上限或未开启队列时丢弃，调用时需持有 mu
+func (w *httpWriter) enqueueLocked(batch [][]byte) {
+	size := 0
+	for _, line := range batch {
+		size += len(line)
+	}
+	if w.config.QueueMaxBytes < 0 || w.stats.SpillBytes+int64(size) > w.config.QueueMaxBytes {
+		w.stats.Dropped += uint64(len(batch))
+		return
+	}
+
+	w.queueSeq++
+	name := path.Join(w.config.QueueDir, fmt.Sprintf("%020d-%06d.ndjson", time.Now().UnixNano(), w.queueSeq))
+	if err := os.WriteFile(name, bytes.Join(batch, nil), 0o644); err != nil {
+		w.stats.Dropped += uint64(len(batch))
+		w.stats.LastError, w.stats.LastErrorTime = err.Error(), time.Now()
+		return
+	}
+	w.stats.Spilled += uint64(len(batch))
+	w.stats.SpillBytes += int64(size)
+}
+
+// queueFiles 按写入顺序返回本地队列中的文件
+func (w *httpWriter) queueFiles() []string {
+	if w.config.QueueMaxBytes < 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(w.config.QueueDir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".ndjson") {
+			files = append(files, path.Join(w.config.QueueDir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (w *httpWriter) queueBytes() int64 {
+	var total int64
+	for _, file := range w.queueFiles() {
+		if info, err := os.Stat(file); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// sendQueued 按顺序重发本地队列中的批次，返回队列是否已清空
+func (w *httpWriter) sendQueued(retry bool) bool {
+	for _, file := range w.queueFiles() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		batch := bytes.SplitAfter(data, []byte{'\n'})
+		if len(batch) > 0 && len(batch[len(batch)-1]) == 0 {
+			batch = batch[:len(batch)-1]
+		}
+
+		err = w.send(batch, retry)
+		if err != nil && !errors.Is(err, errHTTPNotRetryable) {
+			return false
+		}
+
+		_ = os.Remove(file)
+		w.mu.Lock()
+		w.stats.SpillBytes -= int64(len(data))
+		if err != nil {
+			w.stats.Dropped += uint64(len(batch))
+		}
+		w.mu.Unlock()
+	}
+	return true
+}
+
+// Stats 返回输出的运行状态
+func (w *httpWriter) Stats() output_config.Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Sync 立即发送待发送的日志，每个批次只尝试一次，失败的批次写入本地队列
+func (w *httpWriter) Sync() error {
+	w.flush(false)
+	return nil
+}
+
+// Close 停止后台发送，尝试发送剩余的日志，失败的批次保留在本地队列中
+func (w *httpWriter) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+		w.flush(false)
+	})
+	return nil
+}
@@ -0,0 +1,297 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/output_config"
+)
+
+// httpRecorder 记录收到的请求体，按顺序返回 statuses 中的状态码，之后返回 200
+type httpRecorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	statuses []int
+	header   http.Header // 非 200 响应附带的响应头
+	response string      // 200 响应体
+	server   *httptest.Server
+}
+
+func newHTTPRecorder(t *testing.T, statuses ...int) *httpRecorder {
+	t.Helper()
+	r := &httpRecorder{statuses: statuses, header: http.Header{}}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(req.Body)
+			if err != nil {
+				t.Errorf("invalid gzip body: %v", err)
+				return
+			}
+			body = gz
+		}
+		data, _ := io.ReadAll(body)
+
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(data))
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		response := r.response
+		r.mu.Unlock()
+
+		if status != http.StatusOK {
+			for key := range r.header {
+				w.Header().Set(key, r.header.Get(key))
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *httpRecorder) Bodies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+func (r *httpRecorder) setStatuses(statuses ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = statuses
+}
+
+func newTestHTTPWriter(t *testing.T, url string, config output_config.HTTPConfig) *httpWriter {
+	t.Helper()
+	if config.QueueDir == "" {
+		config.QueueDir = t.TempDir()
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = time.Hour
+	}
+	w, err := newHTTPWriter(url, &config, "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+func writeLines(t *testing.T, w *httpWriter, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if _, err := fmt.Fprintf(w, "{\"n\":%d}\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func lineCounts(bodies []string) []int {
+	counts := make([]int, 0, len(bodies))
+	for _, body := range bodies {
+		counts = append(counts, strings.Count(body, "\n"))
+	}
+	return counts
+}
+
+func init() {
+	httpRetryBackoff = 10 * time.Millisecond
+}
+
+func TestHTTPWriterBatching(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		rec := newHTTPRecorder(t)
+		w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{BatchSize: 3})
+		// 一次写入 6 行，达到批次大小后后台按 3 行一批发送
+		_, _ = io.WriteString(w, strings.Repeat("{}\n", 6))
+		waitFor(t, func() bool { return len(rec.Bodies()) == 2 })
+		writeLines(t, w, 0, 1)
+		_ = w.Close()
+		if got := fmt.Sprint(lineCounts(rec.Bodies())); got != "[3 3 1]" {
+			t.Errorf("batches: got %s, want [3 3 1]", got)
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		rec := newHTTPRecorder(t)
+		// 每行 9 字节，每批最多 2 行
+		w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{BatchBytes: 20, Compression: "none"})
+		writeLines(t, w, 0, 5)
+		_ = w.Close()
+		if got := fmt.Sprint(lineCounts(rec.Bodies())); got != "[2 2 1]" {
+			t.Errorf("batches: got %s, want [2 2 1]", got)
+		}
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		rec := newHTTPRecorder(t)
+		w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{FlushInterval: 20 * time.Millisecond})
+		writeLines(t, w, 0, 1)
+		waitFor(t, func() bool { return len(rec.Bodies()) == 1 })
+		if body := rec.Bodies()[0]; body != "{\"n\":0}\n" {
+			t.Errorf("body: got %q", body)
+		}
+	})
+}
+
+func TestHTTPWriterRetry(t *testing.T) {
+	rec := newHTTPRecorder(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	rec.header.Set("Retry-After", "1")
+	w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{})
+
+	start := time.Now()
+	writeLines(t, w, 0, 2)
+	w.flush(true)
+
+	if n := len(rec.Bodies()); n != 3 {
+		t.Fatalf("got %d requests, want 3", n)
+	}
+	// 两次重试都遵循 Retry-After
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("Retry-After not honoured: retried after %s", elapsed)
+	}
+	if stats := w.Stats(); stats.Written != 2 || stats.Spilled != 0 {
+		t.Errorf("stats: written %d spilled %d", stats.Written, stats.Spilled)
+	}
+}
+
+func TestHTTPWriterRetriesDisabled(t *testing.T) {
+	rec := newHTTPRecorder(t, http.StatusInternalServerError)
+	w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{Retries: -1})
+	writeLines(t, w, 0, 2)
+	w.flush(true)
+
+	if n := len(rec.Bodies()); n != 1 {
+		t.Fatalf("got %d requests, want 1", n)
+	}
+	if stats := w.Stats(); stats.Spilled != 2 {
+		t.Errorf("failed batch not queued: spilled %d", stats.Spilled)
+	}
+}
+
+func TestHTTPWriterDropsOn4xx(t *testing.T) {
+	rec := newHTTPRecorder(t, http.StatusBadRequest)
+	w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{})
+	writeLines(t, w, 0, 3)
+	w.flush(true)
+
+	if n := len(rec.Bodies()); n != 1 {
+		t.Fatalf("got %d requests, want 1", n)
+	}
+	if stats := w.Stats(); stats.Dropped != 3 || stats.Spilled != 0 || stats.Written != 0 {
+		t.Errorf("stats: dropped %d spilled %d written %d", stats.Dropped, stats.Spilled, stats.Written)
+	}
+}
+
+func TestHTTPWriterQueueReplayAfterRestart(t *testing.T) {
+	failures := make([]int, 10)
+	for i := range failures {
+		failures[i] = http.StatusInternalServerError
+	}
+	rec := newHTTPRecorder(t, failures...)
+	dir := t.TempDir()
+	config := output_config.HTTPConfig{BatchSize: 2, Retries: -1, QueueDir: dir}
+
+	// 服务不可用时，批次按顺序写入本地队列
+	w := newTestHTTPWriter(t, rec.server.URL, config)
+	writeLines(t, w, 0, 2)
+	w.flush(true)
+	writeLines(t, w, 2, 5)
+	w.flush(true)
+	_ = w.Close()
+	if stats := w.Stats(); stats.Spilled != 5 || stats.Written != 0 {
+		t.Fatalf("stats before restart: spilled %d written %d", stats.Spilled, stats.Written)
+	}
+
+	// 重启后先按顺序重发队列中的批次，再发送新的日志
+	failed := len(rec.Bodies())
+	rec.setStatuses()
+	w = newTestHTTPWriter(t, rec.server.URL, config)
+	if stats := w.Stats(); stats.SpillBytes == 0 {
+		t.Fatal("queue not found after restart")
+	}
+	writeLines(t, w, 5, 6)
+	w.flush(true)
+
+	var lines []string
+	for _, body := range rec.Bodies()[failed:] {
+		lines = append(lines, strings.Split(strings.TrimSpace(body), "\n")...)
+	}
+	want := []string{`{"n":0}`, `{"n":1}`, `{"n":2}`, `{"n":3}`, `{"n":4}`, `{"n":5}`}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("replayed lines: got %v, want %v", lines, want)
+	}
+	if stats := w.Stats(); stats.SpillBytes != 0 || stats.Written != 6 {
+		t.Errorf("stats after replay: spill bytes %d written %d", stats.SpillBytes, stats.Written)
+	}
+}
+
+func TestHTTPWriterLokiBody(t *testing.T) {
+	rec := newHTTPRecorder(t)
+	w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{
+		Preset: output_config.PresetLoki,
+		Labels: map[string]string{"env": "test"},
+	})
+	_, _ = io.WriteString(w, `{"level":"INFO","timestamp":"2024-01-02T03:04:05.000000006Z","msg":"a"}`+"\n")
+	_, _ = io.WriteString(w, `{"level":"ERROR","timestamp":"2024-01-02T03:04:05Z","msg":"b"}`+"\n")
+	w.flush(true)
+
+	var payload struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(rec.Bodies()[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(payload.Streams))
+	}
+	errStream, infoStream := payload.Streams[0], payload.Streams[1]
+	if errStream.Stream["level"] != "error" || infoStream.Stream["level"] != "info" {
+		t.Errorf("stream levels: %v %v", errStream.Stream, infoStream.Stream)
+	}
+	if infoStream.Stream["service_name"] != "svc" || infoStream.Stream["env"] != "test" {
+		t.Errorf("stream labels: %v", infoStream.Stream)
+	}
+	if ts := infoStream.Values[0][0]; ts != "1704164645000000006" {
+		t.Errorf("timestamp: got %s", ts)
+	}
+	if line := infoStream.Values[0][1]; !strings.Contains(line, `"msg":"a"`) || strings.HasSuffix(line, "\n") {
+		t.Errorf("line: got %q", line)
+	}
+}
+
+func TestHTTPWriterBulkBody(t *testing.T) {
+	rec := newHTTPRecorder(t)
+	rec.response = `{"errors":true,"items":[{"create":{"status":201}},{"create":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`
+	w := newTestHTTPWriter(t, rec.server.URL, output_config.HTTPConfig{Preset: output_config.PresetElasticsearch, Index: "logs-test"})
+	writeLines(t, w, 0, 2)
+	w.flush(true)
+
+	want := "{\"create\":{\"_index\":\"logs-test\"}}\n{\"n\":0}\n{\"create\":{\"_index\":\"logs-test\"}}\n{\"n\":1}\n"
+	if body := rec.Bodies()[0]; body != want {
+		t.Errorf("body: got %q, want %q", body, want)
+	}
+	stats := w.Stats()
+	if stats.Written != 1 || stats.Dropped != 1 {
+		t.Errorf("stats: written %d dropped %d", stats.Written, stats.Dropped)
+	}
+	if !strings.Contains(stats.LastError, "mapper_parsing_exception") {
+		t.Errorf("last error: %q", stats.LastError)
+	}
+}
@@ -1,6 +1,12 @@
 package logger
 
-import "github.com/everfir/logger-go/structs/field"
+import (
+	"runtime"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+)
 
 // Logger 定义日志接口
 type Logger interface {
@@ -9,4 +15,23 @@ type Logger interface {
 	Warn(msg string, fields ...field.Field)
 	Error(msg string, fields ...field.Field)
 	Fatal(msg string, fields ...field.Field)
+	// Critical 输出FATAL级别日志但不退出进程，用于记录panic等即将导致崩溃的错误
+	Critical(msg string, fields ...field.Field)
+	// Log 输出指定级别的日志，caller 不为 nil 时作为调用位置，用于 slog 等适配器；FATAL 级别不退出进程
+	Log(level log_level.Level, caller *runtime.Frame, msg string, fields ...field.Field)
+
+	// Sync 刷新所有输出的缓冲
+	Sync() error
+	// Close 刷新缓冲并关闭文件等输出，关闭后不应再写入日志
+	Close() error
+}
+
+// StatsProvider 可以提供输出运行状态的日志器
+type StatsProvider interface {
+	Stats() []output_config.Stats
+}
+
+// statsReporter 提供运行状态的单个输出
+type statsReporter interface {
+	Stats() output_config.Stats
 }
@@ -0,0 +1,444 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everfir/logger-go/structs/output_config"
+)
+
+// 网络输出格式
+const (
+	netFormatJSON    = "json"    // 换行分隔的JSON
+	netFormatForward = "forward" // Fluent Forward 协议 Message 模式
+)
+
+const (
+	netDialTimeout    = 5 * time.Second
+	netWriteTimeout   = 5 * time.Second
+	netMaxBackoff     = 30 * time.Second
+	netRetryInterval  = time.Second
+	defaultSpillLimit = 64 << 20
+	netReplayChunk    = 64 << 10 // 每次重发的最大字节数
+)
+
+// isNetworkTarget 判断输出目标是否为网络输出，需在 isSyslogTarget 之后判断
+func isNetworkTarget(target string) bool {
+	for _, prefix := range []string{"tcp://", "udp://", "unix://"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// netWriter 将换行分隔的JSON日志发送到 TCP/UDP/unix socket，
+// 连接及重发暂存的日志在后台 goroutine 中进行：断开时按指数退避重连，期间的日志暂存到本地文件，重连后按顺序分块重发
+type netWriter struct {
+	target  string
+	network string
+	addr    string
+	format  string
+	tag     string
+
+	spillPath  string
+	spillLimit int64
+
+	mu          sync.Mutex
+	conn        net.Conn
+	backoff     time.Duration
+	nextDial    time.Time
+	spill       *os.File
+	spillSize   int64 // 暂存文件大小
+	spillOffset int64 // 已重发到的位置，之前的内容已发送
+	stats       output_config.Stats
+	everDialed  bool
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// sendResult 一次发送的结果
+type sendResult struct {
+	sent    int    // 已发送的原始日志字节数
+	dropped uint64 // forward 格式无法转换而丢弃的行数
+	dropErr error  // 最近一次转换失败的错误
+}
+
+// newNetWriter 解析网络输出目标，支持参数：
+// format=json|forward、tag=Fluent tag（默认服务名）、spill=暂存文件路径、spill_max=暂存文件最大字节数（0 表示不暂存）
+func newNetWriter(target, serviceName string) (*netWriter, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network target %q: %w", target, err)
+	}
+
+	w := &netWriter{
+		target:     target,
+		network:    u.Scheme,
+		addr:       u.Host,
+		format:     netFormatJSON,
+		tag:        serviceName,
+		spillLimit: defaultSpillLimit,
+		kick:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if u.Scheme == "unix" {
+		w.addr = u.Path
+	}
+	if w.addr == "" {
+		return nil, fmt.Errorf("network target %q: missing address", target)
+	}
+
+	query := u.Query()
+	if format := query.Get("format"); format != "" {
+		if format != netFormatJSON && format != netFormatForward {
+			return nil, fmt.Errorf("network target %q: unsupported format %q", target, format)
+		}
+		w.format = format
+	}
+	if tag := query.Get("tag"); tag != "" {
+		w.tag = tag
+	}
+	if w.tag == "" {
+		w.tag = "logger"
+	}
+	if limit := query.Get("spill_max"); limit != "" {
+		if w.spillLimit, err = strconv.ParseInt(limit, 10, 64); err != nil || w.spillLimit < 0 {
+			return nil, fmt.Errorf("network target %q: invalid spill_max %q", target, limit)
+		}
+	}
+
+	w.spillPath = query.Get("spill")
+	if w.spillPath == "" && w.spillLimit > 0 {
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.NewReplacer("/", "_", ":", "_", "?", "_", "&", "_", "=", "_").Replace(u.Scheme + "_" + w.addr)
+		w.spillPath = path.Join(dir, "log", "spill", name+".spill")
+	}
+	if w.spillLimit > 0 {
+		if err = w.openSpill(); err != nil {
+			return nil, err
+		}
+	}
+
+	w.stats.Target = target
+	w.wake()
+	go w.run()
+	return w, nil
+}
+
+// openSpill 打开暂存文件，进程重启后继续重发上次未发送的日志
+func (w *netWriter) openSpill() error {
+	if err := os.MkdirAll(path.Dir(w.spillPath), os.ModePerm); err != nil {
+		return fmt.Errorf("create spill directory failed: %w", err)
+	}
+	f, err := os.OpenFile(w.spillPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open spill file failed: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.spill, w.spillSize = f, info.Size()
+	w.stats.SpillBytes = w.spillSize
+	return nil
+}
+
+// Write 已连接且没有待重发的日志时直接发送，否则暂存到本地文件并通知后台 goroutine 连接及重发，不会在调用方阻塞于连接
+func (w *netWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	if w.conn != nil && w.pending() == 0 {
+		res, err := w.send(w.conn, p)
+		w.account(p, res)
+		if err == nil {
+			return len(p), nil
+		}
+		// 未发送的部分暂存，避免重复发送已发送的日志
+		w.fail(err)
+		p = p[res.sent:]
+	}
+
+	err := w.spillLines(p, uint64(bytes.Count(p, []byte{'\n'})))
+	w.wake()
+	if err != nil {
+		return n - len(p), err
+	}
+	return n, nil
+}
+
+// pending 返回暂存文件中待重发的字节数，调用时需持有锁
+func (w *netWriter) pending() int64 {
+	return w.spillSize - w.spillOffset
+}
+
+// wake 通知后台 goroutine 连接及重发
+func (w *netWriter) wake() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// account 根据发送结果更新统计，调用时需持有锁
+func (w *netWriter) account(data []byte, res sendResult) {
+	lines := uint64(bytes.Count(data[:res.sent], []byte{'\n'}))
+	w.stats.Written += lines - res.dropped
+	w.stats.Dropped += res.dropped
+	if res.dropErr != nil {
+		w.stats.LastError, w.stats.LastErrorTime = res.dropErr.Error(), time.Now()
+	}
+}
+
+// connect 未连接且不在退避期间时尝试连接，连接时不持有锁
+func (w *netWriter) connect() {
+	w.mu.Lock()
+	dial := w.conn == nil && !time.Now().Before(w.nextDial)
+	w.mu.Unlock()
+	if !dial {
+		return
+	}
+
+	conn, err := net.DialTimeout(w.network, w.addr, netDialTimeout)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.conn, w.backoff = conn, 0
+	w.stats.Connected = true
+	if w.everDialed {
+		w.stats.Reconnects++
+	}
+	w.everDialed = true
+}
+
+// fail 记录错误并断开连接，下次连接前等待指数退避时间，调用时需持有锁
+func (w *netWriter) fail(err error) {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	w.stats.Connected = false
+	w.stats.LastError, w.stats.LastErrorTime = err.Error(), time.Now()
+
+	if w.backoff == 0 {
+		w.backoff = netRetryInterval
+	} else if w.backoff *= 2; w.backoff > netMaxBackoff {
+		w.backoff = netMaxBackoff
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+}
+
+// send 按格式发送日志，返回已发送的原始日志字节数
+// 面向流的 JSON 输出直接写入；UDP 每行一个数据报，forward 格式每行转换为一条消息，无法转换的行计入丢弃
+func (w *netWriter) send(conn net.Conn, p []byte) (res sendResult, err error) {
+	_ = conn.SetWriteDeadline(time.Now().Add(netWriteTimeout))
+	if w.network != "udp" && w.format == netFormatJSON {
+		res.sent, err = conn.Write(p)
+		return res, err
+	}
+
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+		}
+
+		msg := line
+		if w.format == netFormatForward {
+			if msg, err = forwardMessage(w.tag, line); err != nil {
+				res.dropped++
+				res.dropErr, err = err, nil
+				msg = nil
+			}
+		}
+		if len(msg) > 0 {
+			if _, err = conn.Write(msg); err != nil {
+				return res, err
+			}
+		}
+		res.sent += len(line)
+		p = p[len(line):]
+	}
+	return res, nil
+}
+
+// replay 从记录的位置开始分块重发暂存的日志，全部发送后清空暂存文件
+// 发送时不持有锁，重发期间新的日志继续写入暂存文件以保证顺序；stop 关闭时在当前分块发送后返回
+func (w *netWriter) replay(stop <-chan struct{}) {
+	buf := make([]byte, netReplayChunk)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		w.mu.Lock()
+		conn, spill, offset, pending := w.conn, w.spill, w.spillOffset, w.pending()
+		w.mu.Unlock()
+		if conn == nil || spill == nil || pending == 0 {
+			return
+		}
+
+		n, err := spill.ReadAt(buf[:min(int64(len(buf)), pending)], offset)
+		if n == 0 && err != nil {
+			w.mu.Lock()
+			w.fail(err)
+			w.mu.Unlock()
+			return
+		}
+		// 只发送完整的行，单行超过分块大小时整块发送
+		chunk := buf[:n]
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			chunk = chunk[:i+1]
+		}
+
+		res, err := w.send(conn, chunk)
+
+		w.mu.Lock()
+		w.account(chunk, res)
+		w.spillOffset += int64(res.sent)
+		if err != nil {
+			if w.conn == conn {
+				w.fail(err)
+			}
+		} else if w.pending() == 0 {
+			if truncErr := w.spill.Truncate(0); truncErr != nil {
+				w.stats.LastError, w.stats.LastErrorTime = truncErr.Error(), time.Now()
+			}
+			w.spillSize, w.spillOffset = 0, 0
+		}
+		w.stats.SpillBytes = w.pending()
+		w.mu.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// spillLines 将日志暂存到本地文件，超过上限时丢弃，调用时需持有锁
+func (w *netWriter) spillLines(p []byte, lines uint64) error {
+	if len(p) == 0 {
+		return nil
+	}
+	if w.spill == nil || w.spillSize+int64(len(p)) > w.spillLimit {
+		w.stats.Dropped += lines
+		return nil
+	}
+
+	n, err := w.spill.Write(p)
+	w.spillSize += int64(n)
+	w.stats.SpillBytes = w.pending()
+	if err != nil {
+		w.stats.Dropped += lines
+		return fmt.Errorf("write spill file failed: %w", err)
+	}
+	w.stats.Spilled += lines
+	return nil
+}
+
+// run 在后台连接并重发暂存的日志，由写入通知或定时触发
+func (w *netWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(netRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.kick:
+		case <-ticker.C:
+		}
+		w.connect()
+		w.replay(w.stop)
+	}
+}
+
+// Stats 返回输出的运行状态
+func (w *netWriter) Stats() output_config.Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+func (w *netWriter) Sync() error {
+	return nil
+}
+
+// Close 尝试发送暂存的日志后断开连接，未发送的日志保留在暂存文件中，下次启动时重发
+func (w *netWriter) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+
+	w.connect()
+	w.replay(nil)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	if w.conn != nil {
+		errs = append(errs, w.conn.Close())
+		w.conn = nil
+	}
+	if w.spill != nil {
+		errs = append(errs, w.spill.Close())
+		w.spill = nil
+	}
+	return errors.Join(errs...)
+}
+
+// forwardMessage 将一行JSON日志转换为 Fluent Forward 的 [tag, time, record] 消息
+func forwardMessage(tag string, line []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	record := make(map[string]interface{})
+	if err := decoder.Decode(&record); err != nil {
+		return nil, fmt.Errorf("convert log to forward message failed: %w", err)
+	}
+
+	t := time.Now()
+	if ts, ok := record["timestamp"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			t = parsed
+		}
+	}
+
+	out := []byte{0x93}
+	out = appendMsgpackString(out, tag)
+	out = appendEventTime(out, t)
+	return appendMsgpack(out, record), nil
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lineServer 接收换行分隔的日志
+type lineServer struct {
+	ln    net.Listener
+	lines chan string
+}
+
+func newLineServer(t *testing.T, addr string) *lineServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &lineServer{ln: ln, lines: make(chan string, 1024)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					s.lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *lineServer) expect(t *testing.T, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-s.lines:
+			if got != w {
+				t.Fatalf("got line %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %q", w)
+		}
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 对端不可用时写入不阻塞，日志暂存后在对端恢复时按顺序分块重发
+func TestNetWriterSpillAndReplay(t *testing.T) {
+	// 占用一个端口后释放，作为暂不可用的对端
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	spill := filepath.Join(t.TempDir(), "net.spill")
+	w, err := newNetWriter("tcp://"+addr+"?spill="+spill, "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 超过一个重发分块，验证分块及顺序
+	var want []string
+	line := strings.Repeat("x", 1000)
+	start := time.Now()
+	for i := 0; i < 200; i++ {
+		l := fmt.Sprintf(`{"n":%d,"pad":%q}`, i, line)
+		want = append(want, l)
+		if _, err := w.Write([]byte(l + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("writes blocked for %s while peer down", elapsed)
+	}
+	waitFor(t, func() bool { return w.Stats().Spilled == 200 })
+
+	server := newLineServer(t, addr)
+	server.expect(t, want...)
+	waitFor(t, func() bool { return w.Stats().SpillBytes == 0 })
+
+	// 重发完成后直接发送
+	if _, err := w.Write([]byte("{\"n\":\"after\"}\n")); err != nil {
+		t.Fatal(err)
+	}
+	server.expect(t, `{"n":"after"}`)
+
+	stats := w.Stats()
+	if stats.Written != 201 || stats.Dropped != 0 {
+		t.Errorf("stats: written %d dropped %d", stats.Written, stats.Dropped)
+	}
+}
+
+// forward 格式下无法转换的行计入丢弃，其余行正常发送
+func TestNetWriterForwardDropsBadLines(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var buf bytes.Buffer
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		tmp := make([]byte, 4096)
+		for {
+			n, err := conn.Read(tmp)
+			buf.Write(tmp[:n])
+			if err != nil || bytes.Count(buf.Bytes(), []byte("svc")) >= 2 {
+				break
+			}
+		}
+		received <- buf.Bytes()
+	}()
+
+	w, err := newNetWriter("tcp://"+ln.Addr().String()+"?format=forward&tag=svc&spill_max=0", "svc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	waitFor(t, func() bool { return w.Stats().Connected })
+
+	if _, err := w.Write([]byte("{\"msg\":\"a\"}\nnot json\n{\"msg\":\"b\"}\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		if !bytes.Contains(data, []byte("a")) || !bytes.Contains(data, []byte("b")) {
+			t.Errorf("valid lines not sent: %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+
+	stats := w.Stats()
+	if stats.Written != 2 || stats.Dropped != 1 || stats.LastError == "" {
+		t.Errorf("stats: written %d dropped %d last error %q", stats.Written, stats.Dropped, stats.LastError)
+	}
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/sampling_config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	defaultSamplingInterval = time.Second
+	defaultSummaryInterval  = time.Minute
+)
+
+// samplingLevels 可采样的日志级别，Critical 使用的 DPanic 按 FATAL 处理
+var samplingLevels = map[zapcore.Level]log_level.Level{
+	zapcore.DebugLevel:  log_level.DebugLevel,
+	zapcore.InfoLevel:   log_level.InfoLevel,
+	zapcore.WarnLevel:   log_level.WarnLevel,
+	zapcore.ErrorLevel:  log_level.ErrorLevel,
+	zapcore.DPanicLevel: log_level.FatalLevel,
+	zapcore.FatalLevel:  log_level.FatalLevel,
+}
+
+// samplingCore 按级别分发到不同参数的 zap 采样器，未配置采样的级别直接写入
+type samplingCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+func (c *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for level, s := range c.samplers {
+		samplers[level] = s.With(fields)
+	}
+	return &samplingCore{Core: c.Core.With(fields), samplers: samplers}
+}
+
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s, ok := c.samplers[ent.Level]; ok {
+		return s.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// samplingReporter 统计被采样丢弃的日志数量，并定期输出汇总日志
+type samplingReporter struct {
+	logger  *zap.Logger
+	dropped map[log_level.Level]*atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// newSamplingCore 根据配置为 core 添加采样，未开启采样时返回 nil reporter
+func newSamplingCore(core zapcore.Core, config *sampling_config.SamplingConfig) (zapcore.Core, *samplingReporter) {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = defaultSamplingInterval
+	}
+
+	r := &samplingReporter{
+		logger:  zap.New(core),
+		dropped: make(map[log_level.Level]*atomic.Uint64),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	hook := zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped != 0 {
+			r.dropped[samplingLevels[ent.Level]].Add(1)
+		}
+	})
+
+	samplers := make(map[zapcore.Level]zapcore.Core)
+	for zapLevel, level := range samplingLevels {
+		s, ok := config.For(level)
+		if !ok {
+			continue
+		}
+		if r.dropped[level] == nil {
+			r.dropped[level] = new(atomic.Uint64)
+		}
+		samplers[zapLevel] = zapcore.NewSamplerWithOptions(core, interval, s.First, s.Thereafter, hook)
+	}
+	if len(samplers) == 0 {
+		return core, nil
+	}
+
+	summary := config.SummaryInterval
+	if summary <= 0 {
+		summary = defaultSummaryInterval
+	}
+	go r.run(summary)
+
+	return &samplingCore{Core: core, samplers: samplers}, r
+}
+
+func (r *samplingReporter) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			r.report()
+			return
+		case <-ticker.C:
+			r.report()
+		}
+	}
+}
+
+// report 输出上次汇总以来被丢弃的日志数量，没有丢弃时不输出
+func (r *samplingReporter) report() {
+	var fields []zap.Field
+	var total uint64
+	for level := log_level.DebugLevel; level <= log_level.FatalLevel; level++ {
+		counter, ok := r.dropped[level]
+		if !ok {
+			continue
+		}
+		if n := counter.Swap(0); n > 0 {
+			fields = append(fields, zap.Uint64("dropped."+level.String(), n))
+			total += n
+		}
+	}
+	if total == 0 {
+		return
+	}
+
+	fields = append(fields, zap.Uint64("dropped", total))
+	r.logger.Warn("[Logger] log entries dropped by sampling", fields...)
+}
+
+// Close 停止汇总并输出剩余的丢弃数量
+func (r *samplingReporter) Close() error {
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+	})
+	return nil
+}
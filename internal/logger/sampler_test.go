@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/sampling_config"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestSampling 创建写入 observer 的采样 core，测试结束时关闭 reporter
+func newTestSampling(t *testing.T, config *sampling_config.SamplingConfig) (zapcore.Core, *samplingReporter, *observer.ObservedLogs) {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	sampled, reporter := newSamplingCore(core, config)
+	if reporter != nil {
+		t.Cleanup(func() { _ = reporter.Close() })
+	}
+	return sampled, reporter, logs
+}
+
+// writeAt 以指定的时间写入 n 条相同的日志
+func writeAt(core zapcore.Core, level zapcore.Level, msg string, at time.Time, n int) {
+	for i := 0; i < n; i++ {
+		if ce := core.Check(zapcore.Entry{Level: level, Message: msg, Time: at}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+}
+
+func countMessage(logs *observer.ObservedLogs, msg string) int {
+	return logs.FilterMessage(msg).Len()
+}
+
+func TestSamplingPerLevel(t *testing.T) {
+	core, _, logs := newTestSampling(t, &sampling_config.SamplingConfig{
+		Interval:      time.Hour,
+		LevelSampling: sampling_config.LevelSampling{First: 2},
+		Levels: map[log_level.Level]sampling_config.LevelSampling{
+			log_level.InfoLevel: {First: 1, Thereafter: 3},
+		},
+		ExemptErrors:    true,
+		SummaryInterval: time.Hour,
+	})
+
+	now := time.Now()
+	writeAt(core, zapcore.DebugLevel, "debug", now, 10)
+	writeAt(core, zapcore.DebugLevel, "other debug", now, 10)
+	writeAt(core, zapcore.InfoLevel, "info", now, 10)
+	writeAt(core, zapcore.WarnLevel, "warn", now, 10)
+	writeAt(core, zapcore.ErrorLevel, "error", now, 10)
+	writeAt(core, zapcore.DPanicLevel, "critical", now, 10)
+
+	tests := []struct {
+		msg  string
+		want int
+	}{
+		// 默认参数：只输出前2条，不同消息分别统计
+		{"debug", 2},
+		{"other debug", 2},
+		// 第1条，之后每3条输出一条：1、4、7、10
+		{"info", 4},
+		{"warn", 2},
+		// ExemptErrors 时 error 及以上不采样
+		{"error", 10},
+		{"critical", 10},
+	}
+	for _, tt := range tests {
+		if got := countMessage(logs, tt.msg); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.msg, got, tt.want)
+		}
+	}
+}
+
+// 每个统计周期重新计数，周期内的突发日志只输出 First 条
+func TestSamplingBurst(t *testing.T) {
+	core, _, logs := newTestSampling(t, &sampling_config.SamplingConfig{
+		Interval:        time.Second,
+		LevelSampling:   sampling_config.LevelSampling{First: 3},
+		SummaryInterval: time.Hour,
+	})
+
+	start := time.Now().Truncate(time.Second)
+	writeAt(core, zapcore.InfoLevel, "burst", start, 100)
+	if got := countMessage(logs, "burst"); got != 3 {
+		t.Fatalf("first interval: got %d, want 3", got)
+	}
+	writeAt(core, zapcore.InfoLevel, "burst", start.Add(500*time.Millisecond), 10)
+	if got := countMessage(logs, "burst"); got != 3 {
+		t.Fatalf("same interval: got %d, want 3", got)
+	}
+	writeAt(core, zapcore.InfoLevel, "burst", start.Add(time.Second), 10)
+	if got := countMessage(logs, "burst"); got != 6 {
+		t.Errorf("next interval: got %d, want 6", got)
+	}
+}
+
+func TestSamplingSummary(t *testing.T) {
+	core, reporter, logs := newTestSampling(t, &sampling_config.SamplingConfig{
+		Interval:        time.Hour,
+		LevelSampling:   sampling_config.LevelSampling{First: 1},
+		SummaryInterval: time.Hour,
+	})
+
+	now := time.Now()
+	writeAt(core, zapcore.DebugLevel, "debug", now, 3)
+	writeAt(core, zapcore.InfoLevel, "info", now, 5)
+	writeAt(core, zapcore.InfoLevel, "other info", now, 2)
+
+	const summaryMsg = "[Logger] log entries dropped by sampling"
+	reporter.report()
+	summaries := logs.FilterMessage(summaryMsg).All()
+	if len(summaries) != 1 {
+		t.Fatalf("got %d summaries", len(summaries))
+	}
+	summary := summaries[0]
+	want := map[string]interface{}{"dropped.debug": uint64(2), "dropped.info": uint64(5), "dropped": uint64(7)}
+	got := summary.ContextMap()
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s: got %v, want %v", key, got[key], value)
+		}
+	}
+	if summary.Level != zapcore.WarnLevel || len(got) != len(want) {
+		t.Errorf("summary: %v %v", summary.Level, got)
+	}
+
+	// 没有新的丢弃时不输出汇总，Close 时输出剩余的丢弃数量
+	reporter.report()
+	writeAt(core, zapcore.WarnLevel, "warn", now, 4)
+	if err := reporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	summaries = logs.FilterMessage(summaryMsg).All()
+	if len(summaries) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(summaries))
+	}
+	if got := summaries[1].ContextMap(); got["dropped.warn"] != uint64(3) || got["dropped"] != uint64(3) {
+		t.Errorf("close summary: %v", got)
+	}
+}
+
+func TestSamplingDisabled(t *testing.T) {
+	core, reporter, logs := newTestSampling(t, &sampling_config.SamplingConfig{ExemptErrors: true})
+	if reporter != nil {
+		t.Error("reporter started without sampling")
+	}
+	writeAt(core, zapcore.InfoLevel, "info", time.Now(), 5)
+	if got := countMessage(logs, "info"); got != 5 {
+		t.Errorf("got %d, want 5", got)
+	}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/output_config"
+	"go.uber.org/zap/zapcore"
+)
+
+// SinkEntry 写入 Sink 的一条日志
+type SinkEntry struct {
+	zapcore.Entry
+	Fields  []zapcore.Field // 包括通过 With 添加的字段
+	Encoded []byte          // 编码后的日志，包含换行符，Write 返回后不可再使用
+}
+
+// Field 返回字段的字符串形式，字段不存在时返回 false
+func (e *SinkEntry) Field(key string) (string, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		f := e.Fields[i]
+		if f.Key != key {
+			continue
+		}
+		if f.Type == zapcore.StringType {
+			return f.String, true
+		}
+
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		return fmt.Sprint(enc.Fields[key]), true
+	}
+	return "", false
+}
+
+// Sink 接收完整日志条目的输出，通过 newSinkCore 适配为 zap core
+// 与 WriteSyncer 不同，Sink 可以读取字段，用于按字段分区等场景
+type Sink interface {
+	Write(entry SinkEntry) error
+	Sync() error
+	Close() error
+}
+
+// SinkFactory 创建 target 为 scheme:// 的输出，openFile 打开 ./log 目录下使用该输出轮转配置的文件，用于发送失败时写入
+// 返回的 Sink 实现 Stats() output_config.Stats 时提供运行状态
+type SinkFactory func(output output_config.OutputConfig, config *log_config.LogConfig, openFile func(name string) (io.WriteCloser, error)) (Sink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = make(map[string]SinkFactory)
+)
+
+// sinkPackages 依赖较重、需要单独导入注册的输出
+var sinkPackages = map[string]string{
+	"kafka": "github.com/everfir/logger-go/sinks/kafka",
+}
+
+// RegisterSink 注册 target 为 scheme:// 的输出，由输出所在的包在 init 中调用
+func RegisterSink(scheme string, factory SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[scheme] = factory
+}
+
+// lookupSink 返回 target 对应的已注册输出；需要导入注册的输出未注册时返回错误
+func lookupSink(target string) (SinkFactory, bool, error) {
+	scheme, _, ok := strings.Cut(target, "://")
+	if !ok {
+		return nil, false, nil
+	}
+
+	sinksMu.RLock()
+	factory, ok := sinks[scheme]
+	sinksMu.RUnlock()
+	if ok {
+		return factory, true, nil
+	}
+	if pkg, ok := sinkPackages[scheme]; ok {
+		return nil, false, fmt.Errorf("output %s requires importing %s", target, pkg)
+	}
+	return nil, false, nil
+}
+
+// sinkCore 将日志编码后连同字段一起写入 Sink
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	fields  []zapcore.Field
+	sink    Sink
+}
+
+func newSinkCore(encoder zapcore.Encoder, sink Sink, enab zapcore.LevelEnabler) zapcore.Core {
+	return &sinkCore{LevelEnabler: enab, encoder: encoder, sink: sink}
+}
+
+func (c *sinkCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for i := range fields {
+		fields[i].AddTo(encoder)
+	}
+	return &sinkCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      encoder,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		sink:         c.sink,
+	}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	if err = c.sink.Write(SinkEntry{
+		Entry:   ent,
+		Fields:  append(c.fields[:len(c.fields):len(c.fields)], fields...),
+		Encoded: buf.Bytes(),
+	}); err != nil {
+		return err
+	}
+
+	// 与 zap 的 ioCore 相同，error 以上级别的日志立即刷新
+	if ent.Level > zapcore.ErrorLevel {
+		return c.sink.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.sink.Sync()
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/output_config"
+)
+
+// memorySink 在内存中记录写入的日志
+type memorySink struct {
+	mu      sync.Mutex
+	entries []string
+	closed  bool
+}
+
+func (s *memorySink) Write(entry SinkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, _ := entry.Field("user")
+	s.entries = append(s.entries, entry.Message+" "+user)
+	return nil
+}
+
+func (s *memorySink) Sync() error { return nil }
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) Stats() output_config.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return output_config.Stats{Target: "memory://test", Written: uint64(len(s.entries))}
+}
+
+func TestRegisterSink(t *testing.T) {
+	sink := &memorySink{}
+	RegisterSink("memory", func(output output_config.OutputConfig, _ *log_config.LogConfig, _ func(string) (io.WriteCloser, error)) (Sink, error) {
+		if output.Target != "memory://test" {
+			t.Errorf("target: %s", output.Target)
+		}
+		return sink, nil
+	})
+
+	config := log_config.DefaultConfig.Clone()
+	config.OutputFiles = nil
+	config.Outputs = []output_config.OutputConfig{{Target: "memory://test"}}
+	l, err := NewZapLogger(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("hello", field.String("user", "alice"))
+
+	if stats := l.(StatsProvider).Stats(); len(stats) != 1 || stats[0].Written != 1 {
+		t.Errorf("stats: %+v", stats)
+	}
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.entries) != 1 || sink.entries[0] != "hello alice" || !sink.closed {
+		t.Errorf("sink: %+v", sink)
+	}
+}
+
+// 未导入 sinks/kafka 时 kafka:// 输出提示需要导入的包
+func TestUnregisteredKafkaSink(t *testing.T) {
+	config := log_config.DefaultConfig.Clone()
+	config.OutputFiles = nil
+	config.Outputs = []output_config.OutputConfig{{Target: "kafka://audit"}}
+	_, err := NewZapLogger(config)
+	if err == nil || !strings.Contains(err.Error(), "sinks/kafka") {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// NewStderrLogger 创建未初始化及关闭后使用的日志器：info 级别、JSON 编码输出到标准错误，不创建任何文件
+func NewStderrLogger() Logger {
+	l, err := NewZapLogger(&log_config.LogConfig{
+		Level:       log_level.InfoLevel,
+		StackTrace:  log_level.FatalLevel,
+		OutputFiles: []string{"stderr"},
+	})
+	if err != nil {
+		// 仅输出到标准错误时不会失败
+		panic(err)
+	}
+	return l
+}
@@ -0,0 +1,506 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslog 消息格式
+const (
+	rfc5424 = "rfc5424"
+	rfc3164 = "rfc3164"
+)
+
+const (
+	syslogDialTimeout   = 5 * time.Second
+	syslogWriteTimeout  = 5 * time.Second
+	syslogMaxBackoff    = 30 * time.Second
+	syslogRetryInterval = time.Second
+	syslogMaxPending    = 1024 // 未连接时最多暂存的消息条数
+
+	// syslogSDID 结构化数据ID，32473 为文档示例使用的企业编号（RFC 5612）
+	syslogSDID = "fields@32473"
+)
+
+// syslogLocalPaths 本地 syslog 的 unix socket 路径
+var syslogLocalPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogFacilities 设施名称与编号的对应关系
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var bufferPool = buffer.NewPool()
+
+// isSyslogTarget 判断输出目标是否为 syslog
+// unix:// 仅在路径为本地 syslog socket 或带有 syslog 参数时作为 syslog，其余作为网络输出
+func isSyslogTarget(target string) bool {
+	for _, prefix := range []string{"syslog://", "syslog+udp://", "syslog+tcp://", "syslog+unix://"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+
+	if !strings.HasPrefix(target, "unix://") {
+		return false
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	for _, path := range syslogLocalPaths {
+		if u.Path == path {
+			return true
+		}
+	}
+	query := u.Query()
+	return query.Has("facility") || strings.HasPrefix(query.Get("format"), "rfc")
+}
+
+// newSyslogOutput 解析 syslog 输出目标，返回编码器及输出
+// 支持 syslog://（本地）、syslog+udp://host:port、syslog+tcp://host:port、syslog+unix:///path、unix:///dev/log，
+// 参数 format=rfc5424|rfc3164、facility=local0、tag=应用名
+func newSyslogOutput(target, serviceName string) (zapcore.Encoder, *syslogWriter, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid syslog target %q: %w", target, err)
+	}
+
+	query := u.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = rfc5424
+	}
+	if format != rfc5424 && format != rfc3164 {
+		return nil, nil, fmt.Errorf("syslog target %q: unsupported format %q", target, format)
+	}
+
+	facility := syslogFacilities["user"]
+	if name := query.Get("facility"); name != "" {
+		var ok bool
+		if facility, ok = syslogFacilities[name]; !ok {
+			return nil, nil, fmt.Errorf("syslog target %q: unsupported facility %q", target, name)
+		}
+	}
+
+	tag := query.Get("tag")
+	if tag == "" {
+		tag = serviceName
+	}
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	w := &syslogWriter{}
+	switch u.Scheme {
+	case "syslog":
+		if u.Host == "" {
+			w.network, w.addrs = "unixgram", syslogLocalPaths
+		} else {
+			w.network, w.addrs = "udp", []string{withDefaultPort(u.Host, "514")}
+		}
+	case "syslog+udp":
+		w.network, w.addrs = "udp", []string{withDefaultPort(u.Host, "514")}
+	case "syslog+tcp":
+		w.network, w.addrs, w.stream = "tcp", []string{withDefaultPort(u.Host, "514")}, true
+		w.octetCounting = format == rfc5424
+	case "unix", "syslog+unix":
+		w.network, w.addrs = "unixgram", []string{u.Path}
+	}
+	if len(w.addrs) == 0 || w.addrs[0] == "" {
+		return nil, nil, fmt.Errorf("syslog target %q: missing address", target)
+	}
+
+	w.start()
+
+	hostname, _ := os.Hostname()
+	enc := &syslogEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		format:           format,
+		facility:         facility,
+		hostname:         nilValue(hostname),
+		tag:              nilValue(tag),
+		pid:              os.Getpid(),
+	}
+	return enc, w, nil
+}
+
+func withDefaultPort(host, port string) string {
+	if host == "" {
+		return ""
+	}
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// nilValue 空值使用 syslog 的 NILVALUE
+func nilValue(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syslogSeverity 将日志级别映射为 syslog 严重级别
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	default:
+		// FATAL 对应 critical
+		return 2
+	}
+}
+
+// syslogEncoder 将日志编码为一行 syslog 消息，RFC 5424 格式下字段写入结构化数据
+type syslogEncoder struct {
+	*zapcore.MapObjectEncoder
+	format   string
+	facility int
+	hostname string
+	tag      string
+	pid      int
+}
+
+func (e *syslogEncoder) Clone() zapcore.Encoder {
+	clone := *e
+	clone.MapObjectEncoder = zapcore.NewMapObjectEncoder()
+	for key, value := range e.Fields {
+		clone.Fields[key] = value
+	}
+	return &clone
+}
+
+func (e *syslogEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	values := e.Clone().(*syslogEncoder)
+	for _, f := range fields {
+		f.AddTo(values.MapObjectEncoder)
+	}
+	if ent.Caller.Defined {
+		values.Fields["caller"] = ent.Caller.TrimmedPath()
+	}
+	if ent.Stack != "" {
+		values.Fields["stacktrace"] = ent.Stack
+	}
+
+	buf := bufferPool.Get()
+	pri := e.facility*8 + syslogSeverity(ent.Level)
+	msg := singleLine(ent.Message)
+	if e.format == rfc3164 {
+		fmt.Fprintf(buf, "<%d>%s %s %s[%d]: %s", pri, ent.Time.Format(time.Stamp), e.hostname, e.tag, e.pid, msg)
+		if len(values.Fields) > 0 {
+			data, err := json.Marshal(values.Fields)
+			if err != nil {
+				buf.Free()
+				return nil, err
+			}
+			buf.AppendByte(' ')
+			buf.AppendString(singleLine(string(data)))
+		}
+	} else {
+		fmt.Fprintf(buf, "<%d>1 %s %s %s %d - ", pri, ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"), e.hostname, e.tag, e.pid)
+		appendStructuredData(buf, values.Fields)
+		buf.AppendByte(' ')
+		buf.AppendString(msg)
+	}
+	buf.AppendByte('\n')
+	return buf, nil
+}
+
+// appendStructuredData 按key排序写入结构化数据，没有字段时写入 NILVALUE
+func appendStructuredData(buf *buffer.Buffer, fields map[string]interface{}) {
+	if len(fields) == 0 {
+		buf.AppendByte('-')
+		return
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf.AppendString("[" + syslogSDID)
+	for _, key := range keys {
+		buf.AppendByte(' ')
+		buf.AppendString(sdName(key))
+		buf.AppendString(`="`)
+		buf.AppendString(sdEscape(sdValue(fields[key])))
+		buf.AppendByte('"')
+	}
+	buf.AppendByte(']')
+}
+
+// sdName PARAM-NAME 最长32个字符，不能包含 '='、空格、']'、'"'
+func sdName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+func sdValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// sdEscape PARAM-VALUE 中的 '"'、'\'、']' 需要转义
+func sdEscape(s string) string {
+	s = singleLine(s)
+	var b strings.Builder
+	for _, r := range s {
+		if r == '"' || r == '\\' || r == ']' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// singleLine 转义换行，保证一条日志对应一行消息
+func singleLine(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+// syslogWriter 按行拆分消息发送到 syslog，连接在后台 goroutine 中进行：
+// 断开时按指数退避重连，连接建立前及断开期间的消息在内存中暂存（最多 syslogMaxPending 条），重连后按顺序发送
+type syslogWriter struct {
+	network       string
+	addrs         []string // 依次尝试的地址
+	stream        bool     // 流式连接需要分帧
+	octetCounting bool     // RFC 6587 octet-counting 分帧，否则以换行分隔
+
+	mu       sync.Mutex
+	conn     net.Conn
+	backoff  time.Duration
+	nextDial time.Time
+	pending  [][]byte // 等待连接后发送的消息
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// start 启动后台连接
+func (w *syslogWriter) start() {
+	w.kick = make(chan struct{}, 1)
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	w.wake()
+	go w.run()
+}
+
+// Write 已连接且没有暂存的消息时直接发送，否则暂存并通知后台 goroutine 连接，不会在调用方阻塞于连接
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i]
+			p = p[i+1:]
+		} else {
+			p = nil
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		if w.conn != nil && len(w.pending) == 0 {
+			if err := w.send(line); err == nil {
+				continue
+			}
+		}
+		w.enqueue(append([]byte(nil), line...))
+	}
+	return n, nil
+}
+
+// send 按连接类型分帧后发送一条消息，失败时断开连接并通知后台 goroutine 重连，调用时需持有锁
+func (w *syslogWriter) send(msg []byte) error {
+	frame := msg
+	switch {
+	case w.octetCounting:
+		frame = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case w.stream || w.network == "unix":
+		frame = append(append([]byte(nil), msg...), '\n')
+	}
+
+	_ = w.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	if _, err := w.conn.Write(frame); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		w.wake()
+		return err
+	}
+	return nil
+}
+
+// enqueue 暂存消息，超过上限时丢弃最旧的消息，调用时需持有锁
+func (w *syslogWriter) enqueue(msg []byte) {
+	if len(w.pending) >= syslogMaxPending {
+		w.pending = w.pending[1:]
+	}
+	w.pending = append(w.pending, msg)
+	w.wake()
+}
+
+// flushPending 按顺序发送暂存的消息，调用时需持有锁
+func (w *syslogWriter) flushPending() {
+	for w.conn != nil && len(w.pending) > 0 {
+		if w.send(w.pending[0]) != nil {
+			return
+		}
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+	}
+}
+
+// wake 通知后台 goroutine 连接
+func (w *syslogWriter) wake() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// run 在后台连接并发送暂存的消息，由写入通知或定时触发
+func (w *syslogWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(syslogRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.kick:
+		case <-ticker.C:
+		}
+		w.connect()
+	}
+}
+
+// connect 未连接且不在退避期间时尝试连接，连接时不持有锁；连接后发送暂存的消息
+func (w *syslogWriter) connect() {
+	w.mu.Lock()
+	dial := w.conn == nil && !time.Now().Before(w.nextDial)
+	network := w.network
+	w.mu.Unlock()
+	if !dial {
+		return
+	}
+
+	conn, network, err := dialSyslog(network, w.addrs)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if w.backoff == 0 {
+			w.backoff = time.Second
+		} else if w.backoff *= 2; w.backoff > syslogMaxBackoff {
+			w.backoff = syslogMaxBackoff
+		}
+		w.nextDial = time.Now().Add(w.backoff)
+		return
+	}
+	w.network, w.conn, w.backoff = network, conn, 0
+	w.flushPending()
+}
+
+// dialSyslog 依次尝试连接 syslog，返回实际使用的网络类型
+func dialSyslog(network string, addrs []string) (net.Conn, string, error) {
+	var err error
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = net.DialTimeout(network, addr, syslogDialTimeout); err == nil {
+			return conn, network, nil
+		}
+		// unix socket 不支持数据报时尝试流式连接
+		if network == "unixgram" {
+			if conn, err = net.DialTimeout("unix", addr, syslogDialTimeout); err == nil {
+				return conn, "unix", nil
+			}
+		}
+	}
+	return nil, network, fmt.Errorf("connect syslog %s %v failed: %w", network, addrs, err)
+}
+
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+// Close 尝试发送暂存的消息后断开连接
+func (w *syslogWriter) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+
+	w.connect()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = nil
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
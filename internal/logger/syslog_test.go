@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newSyslogLogger 创建写入 target 的 zap logger，测试结束时关闭输出
+func newSyslogLogger(t *testing.T, target string) (*zap.Logger, *syslogWriter) {
+	t.Helper()
+	enc, w, err := newSyslogOutput(target, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return zap.New(zapcore.NewCore(enc, w, zapcore.DebugLevel)), w
+}
+
+// acceptOne 接受一个连接，返回其读取端
+func acceptOne(t *testing.T, ln net.Listener) *bufio.Reader {
+	t.Helper()
+	_ = ln.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return bufio.NewReader(conn)
+}
+
+func listenTCP(t *testing.T, addr string) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	return ln
+}
+
+// readOctetCounted 读取一条 RFC 6587 octet-counting 分帧的消息
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	size, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+	if err != nil {
+		t.Fatalf("bad frame length %q", size)
+	}
+	msg := make([]byte, n)
+	if _, err = io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	return string(msg)
+}
+
+func TestSyslogRFC5424OctetCounting(t *testing.T) {
+	ln := listenTCP(t, "127.0.0.1:0")
+	logger, _ := newSyslogLogger(t, "syslog+tcp://"+ln.Addr().String()+"?facility=local0")
+	r := acceptOne(t, ln)
+
+	logger.Info("first\nsecond", zap.String("quote", `a"b]c`), zap.Int("n", 1))
+	logger.Error("plain")
+
+	// local0(16)*8 + info(6) = 134
+	pattern := regexp.MustCompile(`^<134>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ \S+ app \d+ - ` +
+		regexp.QuoteMeta(`[fields@32473 n="1" quote="a\"b\]c"] first\nsecond`) + `$`)
+	if got := readOctetCounted(t, r); !pattern.MatchString(got) {
+		t.Errorf("got %q", got)
+	}
+	// 没有字段时结构化数据为 NILVALUE
+	if got := readOctetCounted(t, r); !regexp.MustCompile(`^<131>1 \S+ \S+ app \d+ - - plain$`).MatchString(got) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSyslogRFC3164(t *testing.T) {
+	ln := listenTCP(t, "127.0.0.1:0")
+	logger, _ := newSyslogLogger(t, "syslog+tcp://"+ln.Addr().String()+"?format=rfc3164&tag=web")
+	r := acceptOne(t, ln)
+
+	logger.Warn("hello", zap.String("k", "v"))
+	logger.Debug("bye")
+
+	// user(1)*8 + warn(4) = 12，TCP 上以换行分帧
+	tests := []*regexp.Regexp{
+		regexp.MustCompile(`^<12>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d \S+ web\[\d+\]: hello {"k":"v"}\n$`),
+		regexp.MustCompile(`^<15>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d \S+ web\[\d+\]: bye\n$`),
+	}
+	for _, pattern := range tests {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pattern.MatchString(line) {
+			t.Errorf("got %q, want %s", line, pattern)
+		}
+	}
+}
+
+func TestSyslogUDPDatagrams(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger, _ := newSyslogLogger(t, "syslog+udp://"+conn.LocalAddr().String())
+	logger.Info("one")
+	logger.Info("two")
+
+	// 每条消息一个数据报，不分帧
+	buf := make([]byte, 4096)
+	for _, want := range []string{"one", "two"} {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); !strings.HasPrefix(got, "<14>1 ") || !strings.HasSuffix(got, " - "+want) {
+			t.Errorf("got %q, want %s", got, want)
+		}
+	}
+}
+
+// syslog 不可用时写入不阻塞，消息暂存到连接建立后按顺序发送
+func TestSyslogWriteDoesNotDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	logger, w := newSyslogLogger(t, "syslog+tcp://"+addr+"?format=rfc3164")
+	start := time.Now()
+	for _, msg := range []string{"a", "b", "c"} {
+		logger.Info(msg)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("writes took %s", elapsed)
+	}
+	waitFor(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		return len(w.pending) == 3 && !w.nextDial.IsZero()
+	})
+
+	r := acceptOne(t, listenTCP(t, addr))
+	for _, want := range []string{"a", "b", "c"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(line, ": "+want+"\n") {
+			t.Errorf("got %q, want %s", line, want)
+		}
+	}
+}
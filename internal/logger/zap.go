@@ -1,14 +1,18 @@
 package logger
 
 import (
+	"errors"
 	"io"
 	"os"
 	"path"
+	"runtime"
+	"syscall"
 	"time"
 
 	. "github.com/everfir/logger-go/structs/field"
 	"github.com/everfir/logger-go/structs/log_config"
 	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
 
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"go.uber.org/zap"
@@ -17,7 +21,9 @@ import (
 
 // zapLogger 实现 Logger 接口
 type zapLogger struct {
-	logger *zap.Logger
+	logger  *zap.Logger
+	closers []io.Closer     // 需要关闭的文件输出
+	stats   []statsReporter // 提供运行状态的输出
 }
 
 // Debug 输出调试级别的日志
@@ -45,6 +51,56 @@ func (l *zapLogger) Fatal(msg string, fields ...Field) {
 	l.logger.Fatal(msg, toZapFields(fields)...)
 }
 
+// Critical 输出FATAL级别的日志但不退出进程，非开发模式下 zap 的 DPanic 不会panic
+func (l *zapLogger) Critical(msg string, fields ...Field) {
+	l.logger.DPanic(msg, toZapFields(fields)...)
+}
+
+// Log 输出指定级别的日志，FATAL 级别与 Critical 相同不退出进程
+func (l *zapLogger) Log(level log_level.Level, caller *runtime.Frame, msg string, fields ...Field) {
+	zapLevel := level.ToZapLevel()
+	if level >= log_level.FatalLevel {
+		zapLevel = zapcore.DPanicLevel
+	}
+
+	ce := l.logger.Check(zapLevel, msg)
+	if ce == nil {
+		return
+	}
+	if caller != nil {
+		ce.Caller = zapcore.EntryCaller{Defined: true, PC: caller.PC, File: caller.File, Line: caller.Line, Function: caller.Function}
+	}
+	ce.Write(toZapFields(fields)...)
+}
+
+// Sync 刷新所有输出的缓冲
+func (l *zapLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+// Close 刷新缓冲并关闭文件输出
+func (l *zapLogger) Close() error {
+	var errs []error
+	if err := l.logger.Sync(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, c := range l.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats 返回网络输出及异步写入的运行状态
+func (l *zapLogger) Stats() []output_config.Stats {
+	ret := make([]output_config.Stats, 0, len(l.stats))
+	for _, s := range l.stats {
+		ret = append(ret, s.Stats())
+	}
+	return ret
+}
+
 // toZapFields 将通用 Field 转换为 zap.Field
 func toZapFields(fields []Field) []zap.Field {
 	zapFields := make([]zap.Field, len(fields))
@@ -54,6 +110,16 @@ func toZapFields(fields []Field) []zap.Field {
 	return zapFields
 }
 
+// objectFields 按顺序编码嵌套对象的字段
+type objectFields []Field
+
+func (fields objectFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range fields {
+		toZapField(f).AddTo(enc)
+	}
+	return nil
+}
+
 // toZapField 根据 Field 类型创建相应的 zap.Field
 func toZapField(f Field) zap.Field {
 	switch f.Type() {
@@ -89,6 +155,8 @@ func toZapField(f Field) zap.Field {
 		return zap.Time(f.Key(), f.Value().(time.Time))
 	case DurationType:
 		return zap.Duration(f.Key(), f.Value().(time.Duration))
+	case ObjectType:
+		return zap.Object(f.Key(), objectFields(f.Value().([]Field)))
 	default:
 		return zap.Any(f.Key(), f.Value())
 	}
@@ -111,38 +179,181 @@ func NewZapLogger(config *log_config.LogConfig) (Logger, error) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
+	if err := config.SamplingConfig.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.AsyncConfig.Validate(); err != nil {
+		return nil, err
+	}
+
 	var cores []zapcore.Core
+	var closers []io.Closer
+	var stats []statsReporter
 
-	for _, filename := range config.OutputFiles {
-		var w zapcore.WriteSyncer
-		if filename == "stdout" || filename == "stderr" {
-			w = zapcore.AddSync(standardWriter(filename))
-		} else {
-			rotateLogger, err := getRotateLogger(filename, config)
-			if err != nil {
-				return nil, err
+	for _, output := range config.AllOutputs() {
+		core, outputClosers, outputStats, err := newOutputCore(output, config, encoderConfig)
+		if err != nil {
+			for _, c := range closers {
+				_ = c.Close()
 			}
-			w = zapcore.AddSync(rotateLogger)
+			return nil, err
 		}
-
-		level := config.Level.ToZapLevel()
-		core := zapcore.NewCore(
-			zapcore.NewJSONEncoder(encoderConfig),
-			w,
-			level,
-		)
 		cores = append(cores, core)
+		closers = append(closers, outputClosers...)
+		if outputStats != nil {
+			stats = append(stats, outputStats)
+		}
 	}
 
 	combinedCore := zapcore.NewTee(cores...)
 
+	// 采样作用于所有输出，汇总日志不参与采样，需在关闭文件前停止
+	if config.SamplingConfig != nil {
+		var reporter *samplingReporter
+		if combinedCore, reporter = newSamplingCore(combinedCore, config.SamplingConfig); reporter != nil {
+			closers = append([]io.Closer{reporter}, closers...)
+		}
+	}
+
+	// 合并连续重复的日志，在采样之前执行，避免重复日志占用采样配额
+	if config.DedupWindow > 0 {
+		dedup := newDedupCore(combinedCore, config.DedupWindow, config.DedupIgnoreFields)
+		combinedCore = dedup
+		closers = append([]io.Closer{dedup}, closers...)
+	}
+
 	options := buildOptions(config)
 	logger := zap.New(combinedCore, options...)
-	return &zapLogger{logger: logger}, nil
+	return &zapLogger{logger: logger, closers: closers, stats: stats}, nil
+}
+
+// newOutputCore 根据输出配置创建 core，返回的 closers 需按顺序关闭，stats 为 nil 表示该输出没有运行状态
+func newOutputCore(output output_config.OutputConfig, config *log_config.LogConfig, encoderConfig zapcore.EncoderConfig) (_ zapcore.Core, _ []io.Closer, stats statsReporter, _ error) {
+	if err := output.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var closers []io.Closer
+	var w zapcore.WriteSyncer
+	var closer io.Closer
+	var encoder zapcore.Encoder
+	var sink Sink
+	factory, registered, err := lookupSink(output.Target)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if output.IsStandard() {
+		w = stdSyncer{standardWriter(output.Target)}
+	} else if isSyslogTarget(output.Target) {
+		syslogEncoder, syslogWriter, err := newSyslogOutput(output.Target, config.ServiceName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		encoder, w, closer = syslogEncoder, syslogWriter, syslogWriter
+	} else if isNetworkTarget(output.Target) {
+		nw, err := newNetWriter(output.Target, config.ServiceName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// 网络输出固定使用JSON编码
+		encoder, w, closer, stats = zapcore.NewJSONEncoder(encoderConfig), nw, nw, nw
+	} else if isHTTPTarget(output.Target) {
+		hw, err := newHTTPWriter(output.Target, output.HTTP, config.ServiceName)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// HTTP 输出固定使用JSON编码
+		encoder, w, closer, stats = zapcore.NewJSONEncoder(encoderConfig), hw, hw, hw
+	} else if registered {
+		s, err := factory(output, config, func(name string) (io.WriteCloser, error) {
+			return getRotateLogger(name, outputRotation(output, config))
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// 注册的输出（如 Kafka）固定使用JSON编码
+		encoder, sink, closer = zapcore.NewJSONEncoder(encoderConfig), s, s
+		stats, _ = s.(statsReporter)
+	} else {
+		rotateLogger, err := getRotateLogger(output.Target, outputRotation(output, config))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		w = zapcore.AddSync(rotateLogger)
+		closer = rotateLogger
+	}
+
+	switch {
+	case encoder != nil:
+		// syslog 使用自身的消息格式，网络、HTTP、Kafka 输出固定使用JSON编码
+	case output.Encoding == output_config.Console:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	var core zapcore.Core
+	level := outputLevel(output, config.Level)
+	if sink != nil {
+		// Sink 自身批量发送，不使用异步写入
+		core = newSinkCore(encoder, sink, level)
+	} else if config.AsyncConfig != nil {
+		// 异步写入需在关闭文件前写完缓冲区
+		aw := newAsyncWriter(w, output.Target, encoder.Clone(), config.AsyncConfig)
+		core = newAsyncCore(encoder, aw, level)
+		closers = append(closers, aw)
+		stats = aw
+	} else {
+		core = zapcore.NewCore(encoder, w, level)
+	}
+	if closer != nil {
+		closers = append(closers, closer)
+	}
+
+	if output.Sampling != nil {
+		var reporter *samplingReporter
+		if core, reporter = newSamplingCore(core, output.Sampling); reporter != nil {
+			closers = append([]io.Closer{reporter}, closers...)
+		}
+	}
+	return core, closers, stats, nil
+}
+
+// outputRotation 返回输出的轮转配置，未配置时使用全局配置
+func outputRotation(output output_config.OutputConfig, config *log_config.LogConfig) output_config.Rotation {
+	rotation := output_config.Rotation{
+		Compress:     config.Compress,
+		MaxBackups:   config.MaxBackups,
+		RotationTime: config.RotationTime,
+	}
+	if output.Rotation != nil {
+		rotation = *output.Rotation
+	}
+	if rotation.RotationTime == 0 {
+		rotation.RotationTime = 1
+	}
+	return rotation
+}
+
+// outputLevel 返回输出的级别范围，最低级别不低于全局级别
+func outputLevel(output output_config.OutputConfig, level log_level.Level) zapcore.LevelEnabler {
+	min := level.ToZapLevel()
+	if l := output.Level.ToZapLevel(); l > min {
+		min = l
+	}
+	if output.MaxLevel == nil {
+		return min
+	}
+
+	// Critical 使用的 DPanic 介于 Error 与 Fatal 之间，最高级别为 fatal 时同样输出
+	max := output.MaxLevel.ToZapLevel()
+	return zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return l >= min && l <= max
+	})
 }
 
 // getRotateLogger 创建一个支持日志轮转的 logger
-func getRotateLogger(filename string, config *log_config.LogConfig) (logger io.Writer, err error) {
+func getRotateLogger(filename string, config output_config.Rotation) (logger *rotatelogs.RotateLogs, err error) {
 	var dir string
 	if dir, err = os.Getwd(); err != nil {
 		return nil, err
@@ -177,7 +388,7 @@ func compressLogFile(file string) {
 func buildOptions(config *log_config.LogConfig) []zap.Option {
 	var opts []zap.Option
 	// 添加调用者跳过级别，确保日志显示正确的调用位置
-	opts = append(opts, zap.AddCallerSkip(2))
+	opts = append(opts, zap.AddCallerSkip(3))
 
 	// 如果 StackTrace 级别不是 FatalLevel，为指定级别及以上的日志添加堆栈跟踪
 	opts = append(opts, zap.AddCaller())
@@ -188,7 +399,7 @@ func buildOptions(config *log_config.LogConfig) []zap.Option {
 }
 
 // standardWriter 获取标准输出或标准错误的 writer
-func standardWriter(path string) io.Writer {
+func standardWriter(path string) *os.File {
 	switch path {
 	case "stdout":
 		return os.Stdout
@@ -199,6 +410,19 @@ func standardWriter(path string) io.Writer {
 	}
 }
 
+// stdSyncer 标准输出为终端或管道时不支持Sync，忽略相应的错误
+type stdSyncer struct {
+	*os.File
+}
+
+func (s stdSyncer) Sync() error {
+	err := s.File.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
+
 func otelSeverityEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
 	switch l {
 	case zapcore.DebugLevel:
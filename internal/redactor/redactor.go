@@ -0,0 +1,266 @@
+package redactor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/redact_config"
+)
+
+// mask 脱敏后的掩码
+const mask = "******"
+
+// defaultKeep truncate 默认保留的字符数
+const defaultKeep = 3
+
+// builtins 内置规则对应的正则
+var builtins = map[string]*regexp.Regexp{
+	redact_config.Email:      regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+	redact_config.Phone:      regexp.MustCompile(`(?:\+?86[- ]?)?\b1[3-9]\d{9}\b`),
+	redact_config.JWT:        regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+	redact_config.CreditCard: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+}
+
+// pattern 一条值匹配规则，check 用于过滤误匹配
+type pattern struct {
+	re    *regexp.Regexp
+	check func(string) bool
+}
+
+// Redactor 按配置对日志消息及字段脱敏，nil 表示不脱敏
+type Redactor struct {
+	keys     []string
+	patterns []pattern
+	strategy redact_config.Strategy
+	keep     int
+	hashKey  []byte
+}
+
+// New 根据配置创建脱敏器，未配置任何规则时返回 nil
+func New(config *redact_config.RedactConfig) (*Redactor, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{strategy: config.Strategy, keep: config.Keep, hashKey: []byte(config.HashKey)}
+	if r.strategy == "" {
+		r.strategy = redact_config.Mask
+	}
+	if r.keep == 0 {
+		r.keep = defaultKeep
+	}
+
+	for _, key := range config.Keys {
+		key = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("invalid redact key %q: %w", key, err)
+		}
+		r.keys = append(r.keys, key)
+	}
+
+	for _, name := range config.Builtins {
+		p := pattern{re: builtins[name]}
+		if name == redact_config.CreditCard {
+			p.check = luhn
+		}
+		r.patterns = append(r.patterns, p)
+	}
+	for _, expr := range config.Patterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re})
+	}
+	return r, nil
+}
+
+// Message 对日志消息脱敏
+func (r *Redactor) Message(msg string) string {
+	if r == nil {
+		return msg
+	}
+	return r.replace(msg)
+}
+
+// Fields 对字段脱敏，返回新的切片，不修改传入的字段
+// key 匹配的字段整体脱敏，其余字符串类型的字段按正则替换匹配的部分，map、结构体等按JSON形式逐层处理
+func (r *Redactor) Fields(fields []field.Field) []field.Field {
+	if r == nil || len(fields) == 0 {
+		return fields
+	}
+
+	ret := make([]field.Field, len(fields))
+	for i, f := range fields {
+		ret[i] = r.field(f)
+	}
+	return ret
+}
+
+func (r *Redactor) field(f field.Field) field.Field {
+	if r.matchKey(f.Key()) {
+		return field.String(f.Key(), r.apply(stringify(f.Value())))
+	}
+	if f.Type() == field.ObjectType {
+		return field.Object(f.Key(), r.Fields(f.Value().([]field.Field))...)
+	}
+
+	var value string
+	switch v := f.Value().(type) {
+	case string:
+		value = v
+	case error:
+		value = v.Error()
+	case fmt.Stringer:
+		value = v.String()
+	case []byte:
+		value = string(v)
+	default:
+		return r.anyField(f)
+	}
+
+	if redacted := r.replace(value); redacted != value {
+		return field.String(f.Key(), redacted)
+	}
+	return f
+}
+
+// anyField 对 map、切片、结构体等类型的值，按JSON形式逐层匹配key并替换字符串，有改动时输出脱敏后的JSON结构
+func (r *Redactor) anyField(f field.Field) field.Field {
+	switch reflect.ValueOf(f.Value()).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+	default:
+		return f
+	}
+
+	data, err := json.Marshal(f.Value())
+	if err != nil {
+		return f
+	}
+	var tree interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if decoder.Decode(&tree) != nil {
+		return f
+	}
+
+	if redacted, changed := r.walk(tree); changed {
+		return field.Any(f.Key(), redacted)
+	}
+	return f
+}
+
+// walk 脱敏JSON解析后的值，返回是否有改动
+func (r *Redactor) walk(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		changed := false
+		for key, item := range v {
+			if r.matchKey(key) {
+				v[key], changed = r.apply(stringify(item)), true
+				continue
+			}
+			if redacted, ok := r.walk(item); ok {
+				v[key], changed = redacted, true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		changed := false
+		for i, item := range v {
+			if redacted, ok := r.walk(item); ok {
+				v[i], changed = redacted, true
+			}
+		}
+		return v, changed
+	case string:
+		redacted := r.replace(v)
+		return redacted, redacted != v
+	default:
+		return v, false
+	}
+}
+
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// replace 按正则替换字符串中的敏感信息
+func (r *Redactor) replace(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.check != nil && !p.check(match) {
+				return match
+			}
+			return r.apply(match)
+		})
+	}
+	return s
+}
+
+// apply 按脱敏方式处理敏感值
+func (r *Redactor) apply(s string) string {
+	switch r.strategy {
+	case redact_config.Hash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:16])
+	case redact_config.Truncate:
+		runes := []rune(s)
+		if len(runes) <= r.keep {
+			return mask
+		}
+		return string(runes[:r.keep]) + mask
+	default:
+		return mask
+	}
+}
+
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// luhn 校验银行卡号，减少对订单号等长数字的误匹配
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,29 @@
+package tracer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// baggageKey 兼容旧版本通过字符串key存放在上下文中的baggage
+const baggageKey = "baggage"
+
+// BaggageFromContext 从上下文中获取baggage，上下文中没有otel baggage时读取兼容key
+func BaggageFromContext(ctx context.Context) baggage.Baggage {
+	if bag := baggage.FromContext(ctx); bag.Len() > 0 {
+		return bag
+	}
+	bag, _ := ctx.Value(baggageKey).(baggage.Baggage)
+	return bag
+}
+
+// ContextWithBaggage 将baggage写入上下文，上下文中已有兼容key时同步更新，避免读到旧值
+func ContextWithBaggage(ctx context.Context, bag baggage.Baggage) context.Context {
+	legacy := ctx.Value(baggageKey) != nil
+	ctx = baggage.ContextWithBaggage(ctx, bag)
+	if legacy {
+		ctx = context.WithValue(ctx, baggageKey, bag)
+	}
+	return ctx
+}
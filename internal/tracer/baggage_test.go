@@ -0,0 +1,47 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func mustBaggage(t *testing.T, key, value string) baggage.Baggage {
+	t.Helper()
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bag
+}
+
+func TestBaggageFromContextPrefersOtel(t *testing.T) {
+	legacy := mustBaggage(t, "openid", "old")
+	ctx := context.WithValue(context.Background(), baggageKey, legacy)
+	if got := BaggageFromContext(ctx).Member("openid").Value(); got != "old" {
+		t.Fatalf("legacy fallback: got %q, want %q", got, "old")
+	}
+
+	ctx = baggage.ContextWithBaggage(ctx, mustBaggage(t, "openid", "new"))
+	if got := BaggageFromContext(ctx).Member("openid").Value(); got != "new" {
+		t.Fatalf("otel baggage: got %q, want %q", got, "new")
+	}
+}
+
+func TestContextWithBaggageUpdatesLegacyKey(t *testing.T) {
+	ctx := ContextWithBaggage(context.Background(), mustBaggage(t, "openid", "x"))
+	if ctx.Value(baggageKey) != nil {
+		t.Fatal("legacy key written to a context without it")
+	}
+
+	ctx = context.WithValue(context.Background(), baggageKey, mustBaggage(t, "openid", "old"))
+	ctx = ContextWithBaggage(ctx, baggage.Baggage{})
+	if got := BaggageFromContext(ctx).Member("openid").Value(); got != "" {
+		t.Fatalf("removed member still visible through legacy key: %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+package tracer
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultPropagator 未初始化otel时使用的传播器
+var defaultPropagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// SpanContextFromContext 从上下文中获取SpanContext，上下文中没有有效的span时读取兼容key
+// Extract 写入兼容key的是远端的父span，必须优先使用之后开始的span
+func SpanContextFromContext(ctx context.Context) trace.SpanContext {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc
+	}
+	if span, ok := ctx.Value("span").(trace.Span); ok && span != nil {
+		return span.SpanContext()
+	}
+	return trace.SpanContext{}
+}
+
+// SpanFromContext 从上下文中获取span，上下文中没有正在记录的span时读取兼容key
+func SpanFromContext(ctx context.Context) trace.Span {
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		return span
+	}
+	for _, key := range []string{"tracing", "span"} {
+		if span, ok := ctx.Value(key).(trace.Span); ok && span != nil {
+			return span
+		}
+	}
+	return trace.SpanFromContext(ctx)
+}
+
+// contextFields 根据配置补充上下文相关字段：自定义handler、baggage以及trace关联信息
+func contextFields(config *tracer_config.TracerConfig, ctx context.Context, fields []field.Field) []field.Field {
+	if config != nil {
+		for key, handler := range config.ContextHandlers {
+			fields = append(fields, field.String(key, handler(ctx)))
+		}
+	}
+
+	for _, member := range BaggageFromContext(ctx).Members() {
+		if !config.LogBaggage(member.Key()) {
+			continue
+		}
+		fields = append(fields, field.String(member.Key(), member.Value()))
+	}
+
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return fields
+	}
+
+	return append(fields,
+		field.String("trace_id", sc.TraceID().String()),
+		field.String("span_id", sc.SpanID().String()),
+		field.String("trace_flags", sc.TraceFlags().String()),
+	)
+}
+
+// ensureSpanContext 上下文中没有有效的trace信息时，按配置生成一个请求级别的trace信息，仅在未开启导出时使用
+func ensureSpanContext(config *tracer_config.TracerConfig, ctx context.Context) context.Context {
+	if config == nil || !config.GenerateTraceID {
+		return ctx
+	}
+	if SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	if _, err := rand.Read(traceID[:]); err != nil {
+		return ctx
+	}
+	if _, err := rand.Read(spanID[:]); err != nil {
+		return ctx
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	})
+	return trace.ContextWithRemoteSpanContext(ctx, sc)
+}
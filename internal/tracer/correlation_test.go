@@ -0,0 +1,68 @@
+package tracer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/otel/propagation"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	remoteSpanID  = "b7ad6b7169203331"
+)
+
+func fieldValue(fields []field.Field, key string) string {
+	for _, f := range fields {
+		if f.Key() == key {
+			s, _ := f.Value().(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// 在 Extract 之后开始的子span必须作为日志的 span_id 及下游的父span
+func TestChildSpanAfterExtract(t *testing.T) {
+	config := tracer_config.DefaultTracerConfig.Clone()
+	tracers := map[string]Tracer{
+		"no_tracer":   NewNoTracer(config),
+		"otel_tracer": &OtelTracer{config: config, propagator: newPropagator(config)},
+	}
+
+	provider := trace_sdk.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+
+	for name, tcer := range tracers {
+		t.Run(name, func(t *testing.T) {
+			ctx := tcer.Extract(context.Background(), propagation.MapCarrier{
+				"traceparent": "00-" + remoteTraceID + "-" + remoteSpanID + "-01",
+			})
+			if got := fieldValue(tcer.FixFields(ctx), "span_id"); got != remoteSpanID {
+				t.Fatalf("span_id before child span: got %q, want %q", got, remoteSpanID)
+			}
+
+			ctx, span := provider.Tracer("test").Start(ctx, "server")
+			defer span.End()
+			childID := span.SpanContext().SpanID().String()
+
+			fields := tcer.FixFields(ctx)
+			if got := fieldValue(fields, "span_id"); got != childID {
+				t.Errorf("logged span_id: got %q, want child %q", got, childID)
+			}
+			if got := fieldValue(fields, "trace_id"); got != remoteTraceID {
+				t.Errorf("logged trace_id: got %q, want %q", got, remoteTraceID)
+			}
+
+			carrier := propagation.MapCarrier{}
+			tcer.Inject(ctx, carrier)
+			if got := carrier["traceparent"]; !strings.Contains(got, "-"+childID+"-") {
+				t.Errorf("injected traceparent %q does not name child span %s", got, childID)
+			}
+		})
+	}
+}
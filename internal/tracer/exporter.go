@@ -0,0 +1,88 @@
+package tracer
+
+import (
+	"strings"
+
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newClient 根据协议创建otlp导出客户端
+// endpoint 为 host:port 时默认不使用TLS，为URL时由scheme决定
+func newClient(config *tracer_config.TracerConfig) otlptrace.Client {
+	isURL := strings.Contains(config.CollectorEndpoint, "://")
+
+	if config.Protocol == tracer_config.GRPC {
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(config.Headers)}
+		if isURL {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(config.CollectorEndpoint))
+		} else {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.CollectorEndpoint), otlptracegrpc.WithInsecure())
+		}
+		if config.Compression == tracer_config.Gzip {
+			opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+		}
+		return otlptracegrpc.NewClient(opts...)
+	}
+
+	compression := otlptracehttp.NoCompression
+	if config.Compression == tracer_config.Gzip {
+		compression = otlptracehttp.GzipCompression
+	}
+
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithHeaders(config.Headers),
+		otlptracehttp.WithCompression(compression),
+	}
+	if isURL {
+		opts = append(opts, otlptracehttp.WithEndpointURL(config.CollectorEndpoint))
+	} else {
+		opts = append(opts, otlptracehttp.WithEndpoint(config.CollectorEndpoint), otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.NewClient(opts...)
+}
+
+// newSampler 根据配置创建采样器
+func newSampler(config *tracer_config.TracerConfig) trace_sdk.Sampler {
+	switch config.Sampler {
+	case tracer_config.AlwaysOff:
+		return trace_sdk.NeverSample()
+	case tracer_config.TraceIDRatio:
+		return trace_sdk.TraceIDRatioBased(config.SamplerArg)
+	case tracer_config.ParentBasedAlwaysOn:
+		return trace_sdk.ParentBased(trace_sdk.AlwaysSample())
+	case tracer_config.ParentBasedAlwaysOff:
+		return trace_sdk.ParentBased(trace_sdk.NeverSample())
+	case tracer_config.ParentBasedTraceIDRatio:
+		return trace_sdk.ParentBased(trace_sdk.TraceIDRatioBased(config.SamplerArg))
+	default:
+		return trace_sdk.AlwaysSample()
+	}
+}
+
+// newPropagator 根据配置创建传播器，未配置时使用 tracecontext,baggage
+func newPropagator(config *tracer_config.TracerConfig) propagation.TextMapPropagator {
+	if config == nil || len(config.Propagators) == 0 {
+		return defaultPropagator
+	}
+
+	var propagators []propagation.TextMapPropagator
+	for _, name := range config.Propagators {
+		switch name {
+		case "tracecontext":
+			propagators = append(propagators, propagation.TraceContext{})
+		case "baggage":
+			propagators = append(propagators, propagation.Baggage{})
+		case "b3":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)))
+		case "b3multi":
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...)
+}
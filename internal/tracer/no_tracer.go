@@ -5,24 +5,51 @@ import (
 
 	"github.com/everfir/logger-go/structs/field"
 	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// NoTracer 不导出span，但仍然透传trace信息并在日志中记录trace关联字段
 type NoTracer struct {
+	config     *tracer_config.TracerConfig
+	propagator propagation.TextMapPropagator
 }
 
-func (tcer *NoTracer) Init() error  { return nil }
-func (tcer *NoTracer) Close() error { return nil }
+func NewNoTracer(config *tracer_config.TracerConfig) *NoTracer {
+	return &NoTracer{config: config, propagator: newPropagator(config)}
+}
+
+func (tcer *NoTracer) Init() error                 { return nil }
+func (tcer *NoTracer) Close(context.Context) error { return nil }
+func (tcer *NoTracer) Flush(context.Context) error { return nil }
 func (tcer *NoTracer) FixFields(ctx context.Context, fields ...field.Field) (ret []field.Field) {
-	return fields
+	return contextFields(tcer.config, ctx, fields)
 }
 func (tcer *NoTracer) Trace(context.Context, log_level.Level, string, ...field.Field) {}
-func (tcer *NoTracer) Start(ctx context.Context, name string) (context.Context, trace.Span) {
-	return ctx, nil
+func (tcer *NoTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return noop.NewTracerProvider().Tracer(name).Start(ctx, name, opts...)
 }
 func (tcer *NoTracer) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
-	return ctx
+	nCtx := tcer.getPropagator().Extract(ctx, carrier)
+	nCtx = ensureSpanContext(tcer.config, nCtx)
+
+	nCtx = context.WithValue(nCtx, "span", trace.SpanFromContext(nCtx))
+	nCtx = ContextWithBaggage(nCtx, baggage.FromContext(nCtx))
+	return nCtx
 }
 func (tcer *NoTracer) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	nCtx := trace.ContextWithSpanContext(ctx, SpanContextFromContext(ctx))
+	nCtx = baggage.ContextWithBaggage(nCtx, BaggageFromContext(ctx))
+	tcer.getPropagator().Inject(nCtx, carrier)
+}
+
+// getPropagator 兼容零值NoTracer
+func (tcer *NoTracer) getPropagator() propagation.TextMapPropagator {
+	if tcer.propagator == nil {
+		return defaultPropagator
+	}
+	return tcer.propagator
 }
@@ -2,9 +2,12 @@ package tracer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/everfir/logger-go/internal/detector"
 	"github.com/everfir/logger-go/structs/field"
 	"github.com/everfir/logger-go/structs/log_level"
 	"github.com/everfir/logger-go/structs/tracer_config"
@@ -13,7 +16,6 @@ import (
 	"go.opentelemetry.io/otel/baggage"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
@@ -23,18 +25,22 @@ import (
 
 func NewOtelTracer(config *tracer_config.TracerConfig, level log_level.Level) *OtelTracer {
 	return &OtelTracer{
-		level:    level,
-		doneChan: make(chan struct{}),
-		config:   config,
+		level:  level,
+		closed: new(atomic.Bool),
+		config: config,
 	}
 }
 
 type OtelTracer struct {
-	doneChan   chan struct{}
+	closed     *atomic.Bool // 与 WithConfig 复制出的实例共享
 	level      log_level.Level
 	config     *tracer_config.TracerConfig
 	provider   *trace_sdk.TracerProvider
 	propagator propagation.TextMapPropagator
+
+	// Init 之前的全局provider及propagator，Close 时恢复
+	prevProvider   trace.TracerProvider
+	prevPropagator propagation.TextMapPropagator
 }
 
 func (tcer *OtelTracer) Init() (err error) {
@@ -42,38 +48,26 @@ func (tcer *OtelTracer) Init() (err error) {
 		return nil
 	}
 
-	// 压缩
-	compression := otlptracehttp.NoCompression
-	if tcer.config.Compression == tracer_config.Gzip {
-		compression = otlptracehttp.GzipCompression
-	}
-
 	var exporter *otlptrace.Exporter
-	exporter, err = otlptrace.New(
-		context.TODO(),
-		otlptracehttp.NewClient(
-			otlptracehttp.WithEndpoint(tcer.config.CollectorEndpoint),
-			otlptracehttp.WithInsecure(),
-			otlptracehttp.WithCompression(compression),
-		),
-	)
+	exporter, err = otlptrace.New(context.TODO(), newClient(tcer.config))
 	if err != nil {
 		return fmt.Errorf("failed to create otelExporter: %w", err)
 	}
 
 	tp := trace_sdk.NewTracerProvider(
-		trace_sdk.WithSampler(trace_sdk.AlwaysSample()), // 全部采样
+		trace_sdk.WithSampler(newSampler(tcer.config)),
 		trace_sdk.WithBatcher(exporter),
 		trace_sdk.WithResource(
 			resource.NewWithAttributes(
 				semconv.SchemaURL,
-				semconv.ServiceNameKey.String(tcer.config.ServiceName),
+				detector.Detect(tcer.config.ServiceName)...,
 			),
 		),
 	)
 
 	// 设置全局的provider，通过GetTracerProvider获取tracer，来开启一个流程
-	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	propagator := newPropagator(tcer.config)
+	tcer.prevProvider, tcer.prevPropagator = otel.GetTracerProvider(), otel.GetTextMapPropagator()
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagator)
 	tcer.provider = tp
@@ -82,56 +76,37 @@ func (tcer *OtelTracer) Init() (err error) {
 	return
 }
 
-func (tcer *OtelTracer) Close() (err error) {
-	if tcer.provider == nil {
+// Close 在ctx超时前刷新并关闭 TracerProvider，可重复调用
+func (tcer *OtelTracer) Close(ctx context.Context) (err error) {
+	if tcer.provider == nil || !tcer.closed.CompareAndSwap(false, true) {
 		return nil
 	}
-	defer close(tcer.doneChan)
 
-	ctx, cancel := context.WithTimeout(context.TODO(), time.Second*20)
-	defer cancel()
+	// 全局provider仍指向当前provider时恢复为 Init 之前的provider及propagator，避免后续span写入已关闭的provider
+	if otel.GetTracerProvider() == trace.TracerProvider(tcer.provider) {
+		otel.SetTracerProvider(tcer.prevProvider)
+		otel.SetTextMapPropagator(tcer.prevPropagator)
+	}
 
 	if err = tcer.provider.ForceFlush(ctx); err != nil {
-		err = fmt.Errorf("failed to flush tracer")
-		return err
+		err = fmt.Errorf("failed to flush tracer: %w", err)
 	}
-	if err = tcer.provider.Shutdown(ctx); err != nil {
-		return err
+	if shutdownErr := tcer.provider.Shutdown(ctx); shutdownErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to shutdown tracer: %w", shutdownErr))
 	}
-	return nil
+	return err
 }
 
-func (tcer *OtelTracer) FixFields(ctx context.Context, fields ...field.Field) []field.Field {
-	for key, handler := range tcer.config.ContextHandlers {
-		fields = append(fields, field.String(key, handler(ctx)))
-	}
-
-	bag, ok := ctx.Value("baggage").(baggage.Baggage)
-	if !ok {
-		bag = baggage.FromContext(ctx)
-	}
-	for _, member := range bag.Members() {
-		fields = append(fields, field.String(member.Key(), member.Value()))
-	}
-
-	// var ok bool
-	var span trace.Span
-	span, ok = ctx.Value("span").(trace.Span)
-	if !ok {
-		span = trace.SpanFromContext(ctx)
-	}
-	if span == nil || !span.SpanContext().IsValid() {
-		return fields
+// Flush 在ctx超时前导出所有已结束的span
+func (tcer *OtelTracer) Flush(ctx context.Context) error {
+	if tcer.provider == nil || tcer.closed.Load() {
+		return nil
 	}
+	return tcer.provider.ForceFlush(ctx)
+}
 
-	traceID := span.SpanContext().TraceID().String()
-	spanID := span.SpanContext().SpanID().String()
-	fields = append(fields,
-		field.String("trace_id", traceID),
-		field.String("span_id", spanID),
-	)
-
-	return fields
+func (tcer *OtelTracer) FixFields(ctx context.Context, fields ...field.Field) []field.Field {
+	return contextFields(tcer.config, ctx, fields)
 }
 
 func (tcer *OtelTracer) Trace(
@@ -159,12 +134,21 @@ func (tcer *OtelTracer) Trace(
 		span.SetStatus(codes.Ok, "success")
 	}
 
-	var attrs []attribute.KeyValue
-	for _, f := range fields {
-		attrs = append(attrs, toOtelField(f))
-	}
+	span.AddEvent(msg, trace.WithAttributes(appendOtelFields(nil, "", fields)...))
+}
 
-	span.AddEvent(msg, trace.WithAttributes(attrs...))
+// appendOtelFields 转换字段，嵌套对象展开为 key.subkey 形式的属性
+func appendOtelFields(attrs []attribute.KeyValue, prefix string, fields []field.Field) []attribute.KeyValue {
+	for _, f := range fields {
+		if f.Type() == field.ObjectType {
+			attrs = appendOtelFields(attrs, prefix+f.Key()+".", f.Value().([]field.Field))
+			continue
+		}
+		kv := toOtelField(f)
+		kv.Key = attribute.Key(prefix) + kv.Key
+		attrs = append(attrs, kv)
+	}
+	return attrs
 }
 
 func toOtelField(f field.Field) attribute.KeyValue {
@@ -207,28 +191,21 @@ func toOtelField(f field.Field) attribute.KeyValue {
 }
 
 func (tcer *OtelTracer) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	// 开启导出时不生成trace信息，由之后开始的span作为根span，否则会成为一个不存在的远端父span的子span
 	nCtx := tcer.propagator.Extract(ctx, carrier)
 
 	nCtx = context.WithValue(nCtx, "span", trace.SpanFromContext(nCtx))
-	nCtx = context.WithValue(nCtx, "baggage", baggage.FromContext(nCtx))
+	nCtx = ContextWithBaggage(nCtx, baggage.FromContext(nCtx))
 	return nCtx
 }
 
 func (tcer *OtelTracer) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
-	var nCtx context.Context = ctx
-	span, ok := ctx.Value("span").(trace.Span)
-	if ok {
-		nCtx = trace.ContextWithSpan(nCtx, span)
-	}
-
-	bag, ok := ctx.Value("baggage").(baggage.Baggage)
-	if ok {
-		nCtx = baggage.ContextWithBaggage(nCtx, bag)
-	}
+	nCtx := trace.ContextWithSpanContext(ctx, SpanContextFromContext(ctx))
+	nCtx = baggage.ContextWithBaggage(nCtx, BaggageFromContext(ctx))
 
 	tcer.propagator.Inject(nCtx, carrier)
 }
 
-func (tcer *OtelTracer) Start(ctx context.Context, name string) (context.Context, trace.Span) {
-	return tcer.provider.Tracer(name).Start(ctx, name)
+func (tcer *OtelTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return tcer.provider.Tracer(name).Start(ctx, name, opts...)
 }
@@ -0,0 +1,120 @@
+package tracer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Close 后恢复 Init 之前的全局provider及propagator
+func TestCloseRestoresGlobalProvider(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	userProvider := trace_sdk.NewTracerProvider()
+	defer func() { _ = userProvider.Shutdown(context.Background()) }()
+	userPropagator := propagation.Baggage{}
+	otel.SetTracerProvider(userProvider)
+	otel.SetTextMapPropagator(userPropagator)
+
+	config := tracer_config.DefaultTracerConfig.Clone()
+	config.Enable = true
+	config.CollectorEndpoint = "127.0.0.1:1"
+	tcer := NewOtelTracer(config, log_level.InfoLevel)
+	if err := tcer.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != trace.TracerProvider(tcer.provider) {
+		t.Fatal("Init did not install its provider")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = tcer.Close(ctx)
+
+	if otel.GetTracerProvider() != trace.TracerProvider(userProvider) {
+		t.Errorf("provider not restored: %T", otel.GetTracerProvider())
+	}
+	if _, ok := otel.GetTextMapPropagator().(propagation.Baggage); !ok {
+		t.Errorf("propagator not restored: %T", otel.GetTextMapPropagator())
+	}
+}
+
+// 开启导出时 GenerateTraceID 不生成远端父span，之后开始的span是采样的根span
+func TestExtractWithoutParentStartsRootSpan(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	config := tracer_config.DefaultTracerConfig.Clone()
+	config.Enable = true
+	config.CollectorEndpoint = "127.0.0.1:1"
+	config.GenerateTraceID = true
+	tcer := NewOtelTracer(config, log_level.InfoLevel)
+	if err := tcer.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = tcer.Close(ctx)
+	}()
+
+	ctx := tcer.Extract(context.Background(), propagation.MapCarrier{})
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		t.Fatalf("Extract created a parent: %v", sc)
+	}
+
+	_, span := tcer.Start(ctx, "request")
+	defer span.End()
+	if !span.SpanContext().IsSampled() {
+		t.Error("root span not sampled")
+	}
+	if parent := span.(trace_sdk.ReadOnlySpan).Parent(); parent.IsValid() {
+		t.Errorf("root span has parent %v", parent)
+	}
+}
+
+// 复用导出器时使用新的日志级别
+func TestWithConfigCopiesLevel(t *testing.T) {
+	config := tracer_config.DefaultTracerConfig.Clone()
+	config.Enable = true
+	config.CollectorEndpoint = "127.0.0.1:1"
+	tcer := NewOtelTracer(config, log_level.InfoLevel)
+
+	reused, ok := WithConfig(tcer, config.Clone(), log_level.DebugLevel)
+	if !ok {
+		t.Fatal("tracer not reused")
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := trace_sdk.NewTracerProvider(trace_sdk.WithSpanProcessor(recorder))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	_, span := provider.Tracer("test").Start(context.Background(), "request")
+	ctx := context.WithValue(context.Background(), "tracing", span)
+
+	tcer.Trace(ctx, log_level.DebugLevel, "old")
+	reused.Trace(ctx, log_level.DebugLevel, "new")
+	span.End()
+
+	var events []string
+	for _, event := range recorder.Ended()[0].Events() {
+		events = append(events, event.Name)
+	}
+	if len(events) != 1 || events[0] != "new" {
+		t.Errorf("events: got %v, want [new]", events)
+	}
+}
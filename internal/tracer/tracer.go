@@ -5,16 +5,40 @@ import (
 
 	"github.com/everfir/logger-go/structs/field"
 	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/tracer_config"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type Tracer interface {
 	Init() error
-	Close() error
+	Close(ctx context.Context) error
+	Flush(ctx context.Context) error
 	FixFields(ctx context.Context, fields ...field.Field) []field.Field
 	Trace(ctx context.Context, level log_level.Level, msg string, fileds ...field.Field)
-	Start(ctx context.Context, name string) (context.Context, trace.Span)
+	Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span)
 	Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context
 	Inject(ctx context.Context, carrier propagation.TextMapCarrier)
 }
+
+// WithConfig 复用已初始化的tracer，仅替换日志字段相关的配置及写入span的日志级别
+// 导出相关配置变化时返回false，需要重新创建tracer
+func WithConfig(tcer Tracer, config *tracer_config.TracerConfig, level log_level.Level) (Tracer, bool) {
+	switch t := tcer.(type) {
+	case *NoTracer:
+		if config.EnableTracing() && config.Validate() {
+			return nil, false
+		}
+		return NewNoTracer(config), true
+	case *OtelTracer:
+		if t.closed.Load() || !t.config.ExporterEqual(config) {
+			return nil, false
+		}
+		ret := *t
+		ret.config = config
+		ret.level = level
+		return &ret, true
+	default:
+		return nil, false
+	}
+}
@@ -1,211 +1,366 @@
 package logger
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"runtime"
+	"strconv"
+	"time"
 
 	"github.com/everfir/logger-go/internal/logger"
 	"github.com/everfir/logger-go/internal/tracer"
 	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/hook"
 	"github.com/everfir/logger-go/structs/log_config"
 	"github.com/everfir/logger-go/structs/log_level"
-	"go.opentelemetry.io/otel/baggage"
+	"github.com/everfir/logger-go/structs/output_config"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// AutoInitEnv 为 true 时在导入包时使用默认配置及环境变量调用 Init
+// 默认导入时没有任何副作用，调用 Init 之前日志以 JSON 输出到标准错误
+const AutoInitEnv = log_config.EnvPrefix + "AUTO_INIT"
+
 func init() {
+	if auto, _ := strconv.ParseBool(os.Getenv(AutoInitEnv)); !auto {
+		return
+	}
 	if err := Init(); err != nil {
-		Error(context.TODO(), fmt.Sprintf("[Logger] Init failed:%s. use console logger", err))
+		Error(context.TODO(), fmt.Sprintf("[Logger] Init failed:%s. use stderr logger", err))
 		return
 	}
 }
 
-type myLogger struct {
-	logger.Logger
-	tracer.Tracer
+// Init 初始化全局日志器
+// 配置优先级从低到高：默认配置 < OTEL_* 环境变量 < LOGGER_* 环境变量 < Option
+func Init(options ...Option) error {
+	config := log_config.DefaultConfig.Clone()
+	applyEnv(config)
+	if err := applyOptions(config, options...); err != nil {
+		return fmt.Errorf("[Logger] Init failed: %w", err)
+	}
 
-	config *log_config.LogConfig
+	stopWatcher()
+	return initWithConfig(config)
 }
 
-var (
-	globalLogger = myLogger{
-		Logger: &logger.ConsoleLogger{},
-		Tracer: &tracer.NoTracer{},
-		config: &log_config.DefaultConfig,
+// InitFromFile 从 YAML/JSON/TOML 配置文件初始化全局日志器，格式由扩展名决定
+// 配置优先级从低到高：默认配置 < OTEL_* 环境变量 < 配置文件 < LOGGER_* 环境变量 < Option
+// 配置中 WatchInterval 大于0时会监听配置文件变化并热加载
+func InitFromFile(path string, options ...Option) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("[Logger] Init failed: %w", err)
+	}
+
+	config, err := fileConfig(path, data, options...)
+	if err != nil {
+		return fmt.Errorf("[Logger] Init failed: %w", err)
 	}
-)
 
-// Init 初始化全局日志器
-func Init(options ...Option) error {
-	// 使用默认配置
-	config := log_config.DefaultConfig // 应用所有选项
+	stopWatcher()
+	if err = initWithConfig(config); err != nil {
+		return err
+	}
+	if config.WatchInterval > 0 {
+		startWatcher(path, data, config.WatchInterval, options)
+	}
+	return nil
+}
+
+// fileConfig 以默认配置为基础，依次应用环境变量、配置文件内容及选项
+func fileConfig(path string, data []byte, options ...Option) (*log_config.LogConfig, error) {
+	format, err := log_config.FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := log_config.DefaultConfig.Clone()
+	applyEnv(config)
+	if err = config.Decode(bytes.NewReader(data), format); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err = applyOptions(config, options...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// applyEnv 应用 OTEL_* 环境变量，按规范忽略不支持的取值并输出警告
+func applyEnv(config *log_config.LogConfig) {
+	for _, warning := range config.ApplyEnv() {
+		Warn(context.TODO(), "[Logger] ignore invalid environment", field.Any("error", warning))
+	}
+}
+
+// applyOptions 应用 LOGGER_* 环境变量及选项
+func applyOptions(config *log_config.LogConfig, options ...Option) error {
+	if err := config.ApplyLoggerEnv(); err != nil {
+		return fmt.Errorf("invalid environment: %w", err)
+	}
+
+	// 应用所有选项
 	for _, option := range options {
-		option(&config)
+		option(config)
 	}
 	config.FixDefault()
+	return nil
+}
+
+// Close 刷新并关闭所有日志输出，在ctx超时前导出并关闭 TracerProvider，返回合并后的错误
+// 关闭后全局日志器恢复为输出到标准错误的 JSON 日志器，可以再次调用 Init
+func Close(ctx context.Context) error {
+	stopWatcher()
+	stopSignalHandler()
+
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	if err := globalLogger.Swap(fallbackLogger()).retire(ctx, true); err != nil {
+		return fmt.Errorf("[Logger] Close failed: %w", err)
+	}
+	return nil
+}
+
+// Sync 刷新所有日志输出的缓冲
+func Sync() error {
+	l := acquire()
+	defer l.release()
 
-	return initWithConfig(&config)
+	return l.Logger.Sync()
 }
 
-func Close() {
-	if globalLogger.Tracer != nil {
-		err := globalLogger.Tracer.Close()
-		if err != nil {
-			Error(context.TODO(), fmt.Sprintf("Tracer close failed:%s", err))
-			return
-		}
+// OutputStats 返回网络输出及异步写入的运行状态，如连接状态、丢弃及暂存的日志数量
+func OutputStats() []output_config.Stats {
+	l := acquire()
+	defer l.release()
+
+	if s, ok := l.Logger.(logger.StatsProvider); ok {
+		return s.Stats()
 	}
+	return nil
 }
 
 // initWithConfig 使用给定的配置初始化日志器
+// 可重复调用：新的日志器原子替换旧的，旧的输出在正在进行的写入完成后关闭，
+// tracing 导出配置变化时旧的 TracerProvider 会被刷新并关闭
 func initWithConfig(config *log_config.LogConfig) (err error) {
-	defer func() {
-		if err != nil {
-			err = fmt.Errorf("[Logger] Init failed: %w", err)
-		}
-	}()
-
-	// TODO 流程优化
-	var dir string
-	if dir, err = os.Getwd(); err != nil {
-		return fmt.Errorf("failed to get current working directory: %w", err)
-	}
-	if err = os.MkdirAll(dir+"/log", os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create log directory %s: %w", dir, err)
+	if _, err = replace(config); err != nil {
+		return fmt.Errorf("[Logger] Init failed: %w", err)
 	}
+	return nil
+}
 
-	var loger logger.Logger
-	if loger, err = logger.NewZapLogger(config); err != nil {
-		return fmt.Errorf("failed to create zap logger with config: %w", err)
+// newLogger 根据配置创建日志输出，./log 目录在文件输出首次写入时才创建
+func newLogger(config *log_config.LogConfig) (logger.Logger, error) {
+	loger, err := logger.NewZapLogger(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zap logger with config: %w", err)
 	}
+	return loger, nil
+}
 
-	var tcer tracer.Tracer = &tracer.NoTracer{}
-	if config.TracerConfig.EnableTracing() && config.TracerConfig.Validate() {
-		tcer = tracer.NewOtelTracer(config.TracerConfig, config.Level)
-		if err = tcer.Init(); err != nil {
-			return err
-		}
+// newTracer 根据配置创建tracer，未开启tracing时仅透传trace信息
+func newTracer(config *log_config.LogConfig) (tracer.Tracer, error) {
+	if !config.TracerConfig.EnableTracing() || !config.TracerConfig.Validate() {
+		return tracer.NewNoTracer(config.TracerConfig), nil
 	}
 
-	globalLogger.Logger = loger
-	globalLogger.Tracer = tcer
-	globalLogger.config = config
-	return nil
+	tcer := tracer.NewOtelTracer(config.TracerConfig, config.Level)
+	if err := tcer.Init(); err != nil {
+		return nil, err
+	}
+	return tcer, nil
 }
 
+// fatalFlushTimeout Fatal 退出前导出span的超时时间
+const fatalFlushTimeout = 5 * time.Second
+
 // 提供全局日志函数
 func Debug(ctx context.Context, msg string, fields ...field.Field) {
-	// env fields
-	fields = append(fields, fixFields(ctx)...)
-
-	// tracing fields
-	if globalLogger.Tracer != nil {
-		globalLogger.Tracer.Trace(ctx, log_level.DebugLevel, msg, fields...)
-		fields = globalLogger.Tracer.FixFields(ctx, fields...)
-	}
-	globalLogger.Logger.Debug(msg, fields...)
+	write(ctx, log_level.DebugLevel, true, nil, msg, fields...)
 }
 
 func Info(ctx context.Context, msg string, fields ...field.Field) {
-	fields = append(fields, fixFields(ctx)...)
-
-	// tracing fields
-	if globalLogger.Tracer != nil {
-		globalLogger.Tracer.Trace(ctx, log_level.InfoLevel, msg, fields...)
-		fields = globalLogger.Tracer.FixFields(ctx, fields...)
-	}
-	globalLogger.Logger.Info(msg, fields...)
+	write(ctx, log_level.InfoLevel, true, nil, msg, fields...)
 }
 
 func Warn(ctx context.Context, msg string, fields ...field.Field) {
-	fields = append(fields, fixFields(ctx)...)
-	if globalLogger.Tracer != nil {
-		globalLogger.Tracer.Trace(ctx, log_level.InfoLevel, msg, fields...)
-		fields = globalLogger.Tracer.FixFields(ctx, fields...)
-	}
-	globalLogger.Logger.Warn(msg, fields...)
+	write(ctx, log_level.WarnLevel, true, nil, msg, fields...)
 }
 
 func Error(ctx context.Context, msg string, fields ...field.Field) {
-	fields = append(fields, fixFields(ctx)...)
-	// tracing fields
-	if globalLogger.Tracer != nil {
-		globalLogger.Tracer.Trace(ctx, log_level.ErrorLevel, msg, fields...)
-		fields = globalLogger.Tracer.FixFields(ctx, fields...)
-	}
-	globalLogger.Logger.Error(msg, fields...)
+	write(ctx, log_level.ErrorLevel, true, nil, msg, fields...)
 }
 
 func Fatal(ctx context.Context, msg string, fields ...field.Field) {
-	fields = append(fields, fixFields(ctx)...)
+	write(ctx, log_level.FatalLevel, true, nil, msg, fields...)
+}
+
+// write 补充字段、调用钩子并写入日志，exit 为 false 时 Fatal 级别日志不退出进程
+// caller 不为 nil 时作为调用位置，否则由 zap 按 CallerSkip 计算，调用层级变化时需同步修改 zap 的 CallerSkip
+func write(ctx context.Context, level log_level.Level, exit bool, caller *runtime.Frame, msg string, fields ...field.Field) {
+	l := acquire()
+	held := true
+	defer func() {
+		if held {
+			l.release()
+		}
+	}()
+
+	// 不需要输出的日志不调用钩子、不脱敏
+	if !l.enabled(level) {
+		return
+	}
+
+	// env fields
+	entry := &hook.Entry{
+		Context: ctx,
+		Level:   level,
+		Message: msg,
+		Fields:  append(fields, l.fixFields(ctx)...),
+	}
+	for _, h := range l.config.Hooks {
+		if !h.Before(entry) {
+			return
+		}
+	}
+	// 脱敏后再写入span事件，tracing补充的字段（如baggage）单独脱敏
+	msg, fields = l.redactor.Message(entry.Message), l.redactor.Fields(entry.Fields)
 
 	// tracing fields
-	if globalLogger.Tracer != nil {
-		globalLogger.Tracer.Trace(ctx, log_level.FatalLevel, msg, fields...)
-		fields = globalLogger.Tracer.FixFields(ctx, fields...)
+	if l.Tracer != nil {
+		l.Tracer.Trace(ctx, level, msg, fields...)
+		n := len(fields)
+		fields = l.Tracer.FixFields(ctx, fields...)
+		if l.redactor != nil {
+			fields = append(fields[:n:n], l.redactor.Fields(fields[n:])...)
+		}
+	}
+	entry.Message, entry.Fields = msg, fields
+
+	// PostHook 可能调用日志函数，在释放读锁后调用，否则与日志器替换并发时会死锁
+	postHooks := l.config.PostHooks
+	if level == log_level.FatalLevel && exit {
+		// 进程将在写入后退出：先导出已结束的span并调用 PostHook，日志输出由 zap 在写入 Fatal 日志时刷新
+		if l.Tracer != nil {
+			flushCtx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+			_ = l.Tracer.Flush(flushCtx)
+			cancel()
+		}
+		l.release()
+		held = false
+		for _, h := range postHooks {
+			h.After(*entry)
+		}
+
+		l, held = acquire(), true
+		if caller != nil {
+			// 与 zap 的 Fatal 相同：写入并刷新后退出
+			l.Logger.Log(level, caller, msg, fields...)
+			_ = l.Logger.Sync()
+			os.Exit(1)
+		}
+		l.Logger.Fatal(msg, fields...)
+		return
+	}
+
+	switch {
+	case caller != nil:
+		l.Logger.Log(level, caller, msg, fields...)
+	case level == log_level.DebugLevel:
+		l.Logger.Debug(msg, fields...)
+	case level == log_level.InfoLevel:
+		l.Logger.Info(msg, fields...)
+	case level == log_level.WarnLevel:
+		l.Logger.Warn(msg, fields...)
+	case level == log_level.ErrorLevel:
+		l.Logger.Error(msg, fields...)
+	default:
+		l.Logger.Critical(msg, fields...)
+	}
+
+	l.release()
+	held = false
+	for _, h := range postHooks {
+		h.After(*entry)
 	}
-	globalLogger.Logger.Fatal(msg, fields...)
 }
 
-// TODO: 待根据环境方案更新
-func fixFields(ctx context.Context) (fields []field.Field) {
-	// 添加容器IP
-	fields = append(fields, field.String("container.ip", globalLogger.config.PodIP))
-	// 添加服务名
-	fields = append(fields, field.String("ServiceName", globalLogger.config.ServiceName))
+// enabled 是否需要处理该级别的日志：达到日志级别，或开启tracing时达到span事件的级别
+// Fatal 总是处理，保证进程退出
+func (l *myLogger) enabled(level log_level.Level) bool {
+	if level >= l.config.Level || level == log_level.FatalLevel {
+		return true
+	}
+	tc := l.config.TracerConfig
+	return tc.EnableTracing() && level >= tc.Level
+}
+
+func (l *myLogger) fixFields(ctx context.Context) (fields []field.Field) {
+	// 上下文中的字段
+	fields = append(fields, FieldsFromContext(ctx)...)
+
+	// 兼容旧字段：容器IP、服务名，未配置时不输出
+	if l.config.PodIP != "" {
+		fields = append(fields, field.String("container.ip", l.config.PodIP))
+	}
+	if l.config.ServiceName != "" {
+		fields = append(fields, field.String("ServiceName", l.config.ServiceName))
+	}
+
+	// 资源信息
+	fields = append(fields, l.resource...)
 
 	return fields
 }
 
+// resourceFields 将资源属性转换为日志字段
+func resourceFields(attrs []attribute.KeyValue) []field.Field {
+	fields := make([]field.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		switch attr.Value.Type() {
+		case attribute.INT64:
+			fields = append(fields, field.Int64(string(attr.Key), attr.Value.AsInt64()))
+		default:
+			fields = append(fields, field.String(string(attr.Key), attr.Value.Emit()))
+		}
+	}
+	return fields
+}
+
 // ----------------------这部分功能，严格来说不算是logger的功能---------------------------------
 
 // Extract 从上下文中提取trace信息
 func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
-	if globalLogger.Tracer == nil {
+	tcer := globalLogger.Load().Tracer
+	if tcer == nil {
 		return ctx
 	}
-	return globalLogger.Tracer.Extract(ctx, carrier)
+	return tcer.Extract(ctx, carrier)
 }
 
-// Inject 将trace信息注入到上下文中
-func Inject(ctx context.Context, carrier propagation.TextMapCarrier, extra map[string]string) {
-	if globalLogger.Tracer == nil {
+// Inject 将trace信息及上下文中的baggage注入到carrier中
+// 需要透传的baggage请先通过 SetBaggage 写入上下文
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	tcer := globalLogger.Load().Tracer
+	if tcer == nil {
 		return
 	}
-
-	var members []baggage.Member
-	bag, ok := ctx.Value("baggage").(baggage.Baggage)
-	if ok {
-		members = bag.Members()
-	}
-
-	var err error
-	for k, v := range extra {
-		member, err := baggage.NewMember(k, v)
-		if err != nil {
-			Warn(ctx, "create baggage member failed", field.Any("error", err))
-			continue
-		}
-		members = append(members, member)
-	}
-
-	bag, err = baggage.New(members...)
-	if err != nil {
-		Warn(ctx, "create baggage failed", field.Any("error", err))
-	}
-	ctx = context.WithValue(ctx, "baggage", bag)
-
-	globalLogger.Tracer.Inject(ctx, carrier)
+	tcer.Inject(ctx, carrier)
 }
 
-// Start 开始一个span
-func Start(ctx context.Context, name string) (context.Context, trace.Span) {
-	if globalLogger.Tracer == nil {
+// Start 开始一个span，opts 可以设置 span 类型、属性等，如 trace.WithSpanKind(trace.SpanKindServer)
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tcer := globalLogger.Load().Tracer
+	if tcer == nil {
 		return ctx, nil
 	}
 
-	ctx, span := globalLogger.Tracer.Start(ctx, name)
+	ctx, span := tcer.Start(ctx, name, opts...)
 	return ctx, span
 }
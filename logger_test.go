@@ -0,0 +1,349 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/internal/detector"
+	_ "github.com/everfir/logger-go/sinks/kafka"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/hook"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// swapForTest 临时替换全局日志器，测试结束时恢复
+func swapForTest(t *testing.T, options ...Option) {
+	t.Helper()
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+
+	restore, err := Swap(append([]Option{WithOutputFiles()}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(restore)
+}
+
+func TestHooks(t *testing.T) {
+	var before atomic.Int32
+	var mu sync.Mutex
+	var entries []hook.Entry
+
+	swapForTest(t,
+		WithLevel(log_level.InfoLevel),
+		WithRedactKeys("password"),
+		WithHooks(hook.HookFunc(func(entry *hook.Entry) bool {
+			before.Add(1)
+			if entry.Message == "drop" {
+				return false
+			}
+			entry.Message = "[hooked] " + entry.Message
+			entry.Fields = append(entry.Fields, field.String("password", "hunter2"))
+			return true
+		})),
+		WithPostHooks(hook.PostHookFunc(func(entry hook.Entry) {
+			mu.Lock()
+			defer mu.Unlock()
+			entries = append(entries, entry)
+		})),
+	)
+
+	ctx := context.Background()
+	Debug(ctx, "below level")
+	Info(ctx, "drop")
+	Warn(ctx, "kept", field.Int("n", 1))
+
+	// 未达到日志级别的日志不调用钩子
+	if got := before.Load(); got != 2 {
+		t.Errorf("Before called %d times, want 2", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(entries) != 1 {
+		t.Fatalf("PostHook got %d entries, want 1: %+v", len(entries), entries)
+	}
+	entry := entries[0]
+	if entry.Level != log_level.WarnLevel || entry.Message != "[hooked] kept" {
+		t.Errorf("entry: %v %q", entry.Level, entry.Message)
+	}
+	// PostHook 看到的是脱敏后的最终字段
+	var password interface{}
+	for _, f := range entry.Fields {
+		if f.Key() == "password" {
+			password = f.Value()
+		}
+	}
+	if password == nil || password == "hunter2" {
+		t.Errorf("password field not redacted: %v", password)
+	}
+}
+
+// PostHook 中可以调用日志函数，与日志器替换并发时不会死锁
+func TestPostHookCanLogDuringReplace(t *testing.T) {
+	var logged atomic.Int32
+	options := []Option{
+		WithOutputFiles(),
+		WithPostHooks(hook.PostHookFunc(func(entry hook.Entry) {
+			if entry.Message == "outer" {
+				Info(context.Background(), "from post hook")
+				logged.Add(1)
+			}
+		})),
+	}
+	swapForTest(t, options...)
+
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := Init(options...); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for i := 0; i < 200; i++ {
+			Info(context.Background(), "outer")
+		}
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("deadlock: logging from a PostHook blocked")
+	}
+	close(stop)
+	<-done
+
+	if got := logged.Load(); got != 200 {
+		t.Errorf("PostHook ran %d times, want 200", got)
+	}
+}
+
+// chdirTemp 切换到临时目录，文件输出写入其中的 ./log
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+// readLog 读取 ./log 下名为 name 的日志文件（含轮转后缀）
+func readLog(t *testing.T, dir, name string) string {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "log", name+".*"))
+	var sb strings.Builder
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+func TestSyncFlushesAsyncOutput(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithAsync(1024, time.Hour))
+
+	Info(context.Background(), "buffered")
+	if strings.Contains(readLog(t, dir, "app.log"), "buffered") {
+		t.Fatal("async output written before Sync")
+	}
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readLog(t, dir, "app.log"), "buffered") {
+		t.Error("Sync did not flush the async output")
+	}
+}
+
+// blockingProducer 在 release 关闭前阻塞，模拟无法连接的 Kafka
+type blockingProducer struct {
+	release chan struct{}
+}
+
+func (p *blockingProducer) Produce(context.Context, []output_config.KafkaMessage) error {
+	<-p.release
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+	dir := chdirTemp(t)
+	t.Cleanup(func() { _ = Close(context.Background()) })
+
+	if err := Init(WithOutputFiles("app.log")); err != nil {
+		t.Fatal(err)
+	}
+	Info(context.Background(), "before close")
+	if err := Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readLog(t, dir, "app.log"), "before close") {
+		t.Error("Close did not flush the file output")
+	}
+	// 关闭后使用标准错误输出，不再写入文件
+	Info(context.Background(), "after close")
+	if strings.Contains(readLog(t, dir, "app.log"), "after close") {
+		t.Error("logged to the closed output")
+	}
+
+	// 输出无法在 ctx 结束前关闭时返回 ctx 的错误
+	producer := &blockingProducer{release: make(chan struct{})}
+	defer close(producer.release)
+	if err := Init(WithOutputFiles(), WithKafkaProducer(producer), WithOutputs(output_config.OutputConfig{Target: "kafka://logs"})); err != nil {
+		t.Fatal(err)
+	}
+	Info(context.Background(), "stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := Close(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close: got %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %s", elapsed)
+	}
+}
+
+// 日志与span资源中的服务名一致，WithServiceName 优先于 SERVICE_NAME
+func TestServiceNameResolvedOnce(t *testing.T) {
+	t.Setenv("SERVICE_NAME", "foo")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv(detector.EnvResourceAttributes, "")
+
+	config := log_config.DefaultConfig.Clone()
+	config.ServiceName = os.Getenv("SERVICE_NAME")
+	if err := applyOptions(config, WithServiceName("bar"), WithTracing(true, "127.0.0.1:1", tracer_config.No)); err != nil {
+		t.Fatal(err)
+	}
+
+	tcer, err := newTracer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tcer.Close(context.Background()) }()
+
+	_, span := tcer.Start(context.Background(), "request")
+	span.End()
+	traceName, _ := span.(trace_sdk.ReadOnlySpan).Resource().Set().Value(semconv.ServiceNameKey)
+
+	var logName string
+	for _, f := range resourceFields(detector.Detect(config.ResourceServiceName())) {
+		if f.Key() == string(semconv.ServiceNameKey) {
+			logName = f.Value().(string)
+		}
+	}
+	if traceName.AsString() != "bar" || logName != "bar" {
+		t.Errorf("service.name: traces %q, logs %q, want bar", traceName.AsString(), logName)
+	}
+}
+
+// 每个输出按各自的级别范围和编码格式写入
+func TestOutputLevelsAndEncoding(t *testing.T) {
+	dir := chdirTemp(t)
+	debug, warn := log_level.DebugLevel, log_level.WarnLevel
+	swapForTest(t,
+		WithLevel(log_level.DebugLevel),
+		WithOutputs(
+			output_config.OutputConfig{Target: "all.log"},
+			output_config.OutputConfig{Target: "debug.log", MaxLevel: &debug},
+			output_config.OutputConfig{Target: "mid.log", Encoding: output_config.Console, Level: log_level.InfoLevel, MaxLevel: &warn},
+			output_config.OutputConfig{Target: "error.log", Level: log_level.ErrorLevel},
+		),
+	)
+
+	ctx := context.Background()
+	Debug(ctx, "debug message")
+	Info(ctx, "info message")
+	Warn(ctx, "warn message")
+	Error(ctx, "error message")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file    string
+		console bool
+		want    []string
+	}{
+		{file: "all.log", want: []string{"debug", "info", "warn", "error"}},
+		{file: "debug.log", want: []string{"debug"}},
+		{file: "mid.log", console: true, want: []string{"info", "warn"}},
+		{file: "error.log", want: []string{"error"}},
+	}
+	for _, tt := range tests {
+		lines := strings.Split(strings.TrimSpace(readLog(t, dir, tt.file)), "\n")
+		if len(lines) != len(tt.want) {
+			t.Errorf("%s: got %d lines, want %v:\n%s", tt.file, len(lines), tt.want, strings.Join(lines, "\n"))
+			continue
+		}
+		for i, line := range lines {
+			if !strings.Contains(line, tt.want[i]+" message") {
+				t.Errorf("%s line %d: %q, want %s", tt.file, i, line, tt.want[i])
+			}
+			if isJSON := strings.HasPrefix(line, "{"); isJSON == tt.console {
+				t.Errorf("%s line %d: console=%v, got %q", tt.file, i, tt.console, line)
+			}
+		}
+	}
+}
+
+// readEntries 解析 ./log 下名为 name 的JSON日志
+func readEntries(t *testing.T, dir, name string) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(readLog(t, dir, name)), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("bad log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// checkCaller 检查日志的调用位置为 file 中的某一行
+func checkCaller(t *testing.T, entry map[string]interface{}, file string) {
+	t.Helper()
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "/"+file+":") {
+		t.Errorf("%v: caller %q, want %s", entry["msg"], caller, file)
+	}
+}
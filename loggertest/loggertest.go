@@ -0,0 +1,220 @@
+// Package loggertest 提供测试中观察日志输出的工具，类似 zap 的 observer
+//
+//	logs := loggertest.New(t)
+//	service.Do(ctx)
+//	logs.FilterMessage("order created").AssertLogged(t, log_level.InfoLevel, "order created", field.String("order_id", "1"))
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/hook"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// LoggedEntry 捕获的一条日志，Fields 为最终写入的字段，包括从上下文中提取的 trace、baggage 等字段及固定字段
+type LoggedEntry struct {
+	Context context.Context
+	Time    time.Time
+	Level   log_level.Level
+	Message string
+	Fields  []field.Field
+}
+
+// Field 返回字段的值，同名字段以最后一个为准
+func (e LoggedEntry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key() == key {
+			return e.Fields[i].Value(), true
+		}
+	}
+	return nil, false
+}
+
+// ContextMap 返回所有字段的 key/value
+func (e LoggedEntry) ContextMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(e.Fields))
+	for _, f := range e.Fields {
+		m[f.Key()] = f.Value()
+	}
+	return m
+}
+
+var (
+	activeMu sync.Mutex
+	active   []string // 生效中的观察者所属的测试名，内层在后
+)
+
+// ObservedLogs 并发安全的日志记录
+type ObservedLogs struct {
+	mu   sync.RWMutex
+	logs []LoggedEntry
+}
+
+// New 将全局日志器临时替换为只记录日志的观察者，测试结束时恢复原日志器
+// 默认级别为 debug、不输出到任何文件、关闭 tracing 导出（仍会从上下文中提取 trace 字段），options 可以覆盖这些配置
+// 记录的是经过钩子及脱敏后、采样及合并前的日志；Fatal 仍会退出进程
+// 观察者替换的是全局日志器，同一时间只能有一个：子测试中可以创建内层的观察者，
+// 其他测试（如并行的测试）的观察者仍生效时调用 Fatal，避免日志被另一个测试记录
+func New(t testing.TB, options ...logger.Option) *ObservedLogs {
+	t.Helper()
+
+	activeMu.Lock()
+	if n := len(active); n > 0 && !strings.HasPrefix(t.Name(), active[n-1]+"/") {
+		owner := active[n-1]
+		activeMu.Unlock()
+		t.Fatalf("loggertest: observer of %s is still active, tests using loggertest.New must not run in parallel", owner)
+	}
+	active = append(active, t.Name())
+	activeMu.Unlock()
+	t.Cleanup(func() {
+		activeMu.Lock()
+		defer activeMu.Unlock()
+		active = active[:len(active)-1]
+	})
+
+	o := &ObservedLogs{}
+	options = append([]logger.Option{
+		logger.WithLevel(log_level.DebugLevel),
+		logger.WithOutputFiles(),
+		func(c *log_config.LogConfig) {
+			c.TracerConfig = c.TracerConfig.Clone()
+			c.TracerConfig.Enable = false
+		},
+	}, options...)
+
+	// 钩子只对达到日志级别的日志调用，级别过滤与日志器一致
+	restore, err := logger.Swap(append(options, logger.WithPostHooks(hook.PostHookFunc(o.add)))...)
+	if err != nil {
+		t.Fatalf("loggertest: %v", err)
+	}
+	t.Cleanup(restore)
+	return o
+}
+
+func (o *ObservedLogs) add(entry hook.Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.logs = append(o.logs, LoggedEntry{
+		Context: entry.Context,
+		Time:    time.Now(),
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  append([]field.Field(nil), entry.Fields...),
+	})
+}
+
+// Len 返回记录的日志条数
+func (o *ObservedLogs) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.logs)
+}
+
+// All 返回所有记录的日志
+func (o *ObservedLogs) All() []LoggedEntry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return append([]LoggedEntry(nil), o.logs...)
+}
+
+// TakeAll 返回并清空所有记录的日志
+func (o *ObservedLogs) TakeAll() []LoggedEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	logs := o.logs
+	o.logs = nil
+	return logs
+}
+
+// Filter 返回满足条件的日志
+func (o *ObservedLogs) Filter(keep func(LoggedEntry) bool) *ObservedLogs {
+	filtered := &ObservedLogs{}
+	for _, entry := range o.All() {
+		if keep(entry) {
+			filtered.logs = append(filtered.logs, entry)
+		}
+	}
+	return filtered
+}
+
+// FilterMessage 返回消息完全相同的日志
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool { return e.Message == msg })
+}
+
+// FilterMessageSnippet 返回消息包含 snippet 的日志
+func (o *ObservedLogs) FilterMessageSnippet(snippet string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool { return strings.Contains(e.Message, snippet) })
+}
+
+// FilterLevel 返回指定级别的日志
+func (o *ObservedLogs) FilterLevel(level log_level.Level) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool { return e.Level == level })
+}
+
+// FilterField 返回包含 key 且值等于 value 的日志
+func (o *ObservedLogs) FilterField(key string, value interface{}) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		v, ok := e.Field(key)
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// FilterFieldKey 返回包含 key 的日志
+func (o *ObservedLogs) FilterFieldKey(key string) *ObservedLogs {
+	return o.Filter(func(e LoggedEntry) bool {
+		_, ok := e.Field(key)
+		return ok
+	})
+}
+
+// AssertLogged 断言记录了指定级别和消息且包含所有 fields 的日志，不满足时标记测试失败并列出已记录的日志
+func (o *ObservedLogs) AssertLogged(t testing.TB, level log_level.Level, msg string, fields ...field.Field) bool {
+	t.Helper()
+
+	matched := o.FilterLevel(level).FilterMessage(msg)
+	for _, f := range fields {
+		matched = matched.FilterField(f.Key(), f.Value())
+	}
+	if matched.Len() > 0 {
+		return true
+	}
+
+	var logged strings.Builder
+	for _, entry := range o.All() {
+		fmt.Fprintf(&logged, "\n\t%s %q %v", entry.Level, entry.Message, entry.ContextMap())
+	}
+	t.Errorf("loggertest: no %s entry %q with fields %v, logged:%s", level, msg, fieldsString(fields), logged.String())
+	return false
+}
+
+// AssertNotLogged 断言没有记录指定级别和消息的日志
+func (o *ObservedLogs) AssertNotLogged(t testing.TB, level log_level.Level, msg string) bool {
+	t.Helper()
+
+	if n := o.FilterLevel(level).FilterMessage(msg).Len(); n > 0 {
+		t.Errorf("loggertest: unexpected %d %s entries %q", n, level, msg)
+		return false
+	}
+	return true
+}
+
+func fieldsString(fields []field.Field) string {
+	items := make([]string, 0, len(fields))
+	for _, f := range fields {
+		items = append(items, fmt.Sprintf("%s=%v", f.Key(), f.Value()))
+	}
+	return "[" + strings.Join(items, " ") + "]"
+}
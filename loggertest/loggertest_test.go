@@ -0,0 +1,181 @@
+package loggertest_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/everfir/logger-go"
+	"github.com/everfir/logger-go/loggertest"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/tracer_config"
+	"go.opentelemetry.io/otel"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingTB 记录 Errorf 的输出，用于断言失败的场景
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNewRecordsLogs(t *testing.T) {
+	logs := loggertest.New(t)
+	ctx := context.Background()
+
+	logger.Debug(ctx, "debug message")
+	logger.Info(ctx, "order created", field.String("order_id", "1"))
+	logger.Info(ctx, "order created", field.String("order_id", "2"))
+	logger.Error(ctx, "payment failed", field.String("order_id", "2"))
+
+	if n := logs.Len(); n != 4 {
+		t.Fatalf("got %d entries, want 4", n)
+	}
+	if n := logs.FilterMessage("order created").Len(); n != 2 {
+		t.Errorf("FilterMessage: got %d entries, want 2", n)
+	}
+	if n := logs.FilterMessageSnippet("order").Len(); n != 2 {
+		t.Errorf("FilterMessageSnippet: got %d entries, want 2", n)
+	}
+	if n := logs.FilterLevel(log_level.ErrorLevel).Len(); n != 1 {
+		t.Errorf("FilterLevel: got %d entries, want 1", n)
+	}
+
+	byOrder := logs.FilterField("order_id", "2")
+	if n := byOrder.Len(); n != 2 {
+		t.Errorf("FilterField: got %d entries, want 2", n)
+	}
+	if n := logs.FilterFieldKey("order_id").Len(); n != 3 {
+		t.Errorf("FilterFieldKey: got %d entries, want 3", n)
+	}
+	if v, ok := byOrder.All()[1].Field("order_id"); !ok || v != "2" {
+		t.Errorf("Field: got %v %v", v, ok)
+	}
+
+	logs.AssertLogged(t, log_level.InfoLevel, "order created", field.String("order_id", "1"))
+	logs.AssertNotLogged(t, log_level.WarnLevel, "order created")
+
+	if n := len(logs.TakeAll()); n != 4 || logs.Len() != 0 {
+		t.Errorf("TakeAll: took %d entries, %d left", n, logs.Len())
+	}
+}
+
+func TestNewRespectsLevel(t *testing.T) {
+	logs := loggertest.New(t, logger.WithLevel(log_level.WarnLevel))
+
+	logger.Info(context.Background(), "ignored")
+	logger.Warn(context.Background(), "kept")
+
+	if n := logs.Len(); n != 1 {
+		t.Fatalf("got %d entries, want 1", n)
+	}
+	logs.AssertLogged(t, log_level.WarnLevel, "kept")
+}
+
+func TestAssertLoggedFailure(t *testing.T) {
+	logs := loggertest.New(t)
+	logger.Info(context.Background(), "order created", field.String("order_id", "1"))
+
+	tb := &recordingTB{TB: t}
+	if logs.AssertLogged(tb, log_level.InfoLevel, "order created", field.String("order_id", "2")) {
+		t.Fatal("AssertLogged matched an entry with a different field value")
+	}
+	if len(tb.errors) != 1 {
+		t.Fatalf("got %d errors, want 1", len(tb.errors))
+	}
+	// 失败信息中列出已记录的日志
+	if msg := tb.errors[0]; !strings.Contains(msg, "order_id=2") || !strings.Contains(msg, "order_id:1") {
+		t.Errorf("error message: %s", msg)
+	}
+
+	tb = &recordingTB{TB: t}
+	if logs.AssertNotLogged(tb, log_level.InfoLevel, "order created") || len(tb.errors) != 1 {
+		t.Errorf("AssertNotLogged passed with a matching entry")
+	}
+}
+
+// 测试结束后恢复原日志器
+func TestNewRestoresLogger(t *testing.T) {
+	outer := loggertest.New(t)
+
+	t.Run("inner", func(t *testing.T) {
+		inner := loggertest.New(t)
+		logger.Info(context.Background(), "inside")
+		if inner.Len() != 1 {
+			t.Errorf("inner observer got %d entries, want 1", inner.Len())
+		}
+	})
+
+	logger.Info(context.Background(), "after")
+	if n := outer.FilterMessage("inside").Len(); n != 0 {
+		t.Errorf("outer observer got %d entries logged inside the subtest", n)
+	}
+	outer.AssertLogged(t, log_level.InfoLevel, "after")
+}
+
+// fatalTB 使用指定的测试名，记录 Fatalf 的输出后结束当前 goroutine
+type fatalTB struct {
+	testing.TB
+	name  string
+	fatal string
+}
+
+func (tb *fatalTB) Helper()      {}
+func (tb *fatalTB) Name() string { return tb.name }
+
+func (tb *fatalTB) Fatalf(format string, args ...any) {
+	tb.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// 另一个测试的观察者仍生效时 New 失败
+func TestNewFailsWhileAnotherObserverIsActive(t *testing.T) {
+	loggertest.New(t)
+
+	tb := &fatalTB{TB: t, name: "TestOther"}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		loggertest.New(tb)
+	}()
+	<-done
+	if !strings.Contains(tb.fatal, t.Name()+" is still active") {
+		t.Errorf("got %q", tb.fatal)
+	}
+}
+
+// 开启 tracing 的日志器恢复后，全局provider恢复为替换前的provider
+func TestSwapRestoresTracerProvider(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	provider := trace_sdk.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	otel.SetTracerProvider(provider)
+
+	restore, err := logger.Swap(
+		logger.WithOutputFiles(),
+		logger.WithTracing(true, "127.0.0.1:1", tracer_config.No),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() == trace.TracerProvider(provider) {
+		t.Fatal("Swap did not install its provider")
+	}
+
+	restore()
+	if otel.GetTracerProvider() != trace.TracerProvider(provider) {
+		t.Errorf("provider not restored: %T", otel.GetTracerProvider())
+	}
+}
@@ -0,0 +1,9 @@
+package logger
+
+import "github.com/go-logr/logr"
+
+// ToLogr 返回写入全局日志器的 logr.Logger，用于 OTel SDK、k8s client 等使用 logr 的库，如 otel.SetLogger(logger.ToLogr())
+// V(0) 对应 info，V(1) 及以上对应 debug；Error 输出为 error 级别并附带 err 字段；WithName 的名称输出在 logger 字段中
+func ToLogr() logr.Logger {
+	return logr.FromSlogHandler(NewSlogHandler(nil))
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+func TestToLogr(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithLevel(log_level.InfoLevel))
+
+	l := ToLogr()
+	l.Info("info", "k", 1)
+	l.V(1).Info("verbose")
+	l.WithName("otel").Error(errors.New("export failed"), "error", "retry", true)
+	if l.V(1).Enabled() || !l.V(0).Enabled() {
+		t.Error("V(1) should be disabled at info level")
+	}
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	if e := entries[0]; e["msg"] != "info" || e["level"] != "INFO" || e["k"] != 1.0 {
+		t.Errorf("info: %v", e)
+	}
+	if e := entries[1]; e["msg"] != "error" || e["level"] != "ERROR" || e["err"] != "export failed" || e["logger"] != "otel" || e["retry"] != true {
+		t.Errorf("error: %v", e)
+	}
+	for _, e := range entries {
+		checkCaller(t, e, "logr_test.go")
+	}
+}
+
+func TestToLogrVerbose(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithLevel(log_level.DebugLevel))
+
+	ToLogr().V(2).Info("verbose")
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != 1 || entries[0]["level"] != "DEBUG" {
+		t.Errorf("got %v", entries)
+	}
+}
@@ -1,8 +1,15 @@
 package logger
 
 import (
+	"time"
+
+	"github.com/everfir/logger-go/structs/async_config"
+	"github.com/everfir/logger-go/structs/hook"
 	"github.com/everfir/logger-go/structs/log_config"
 	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/everfir/logger-go/structs/redact_config"
+	"github.com/everfir/logger-go/structs/sampling_config"
 	"github.com/everfir/logger-go/structs/tracer_config"
 )
 
@@ -58,6 +65,69 @@ func WithErrorFiles(errorFiles ...string) Option {
 	}
 }
 
+// WithOutputs 添加单独配置级别、编码、采样及轮转的输出
+func WithOutputs(outputs ...output_config.OutputConfig) Option {
+	return func(c *log_config.LogConfig) {
+		c.Outputs = append(c.Outputs, outputs...)
+	}
+}
+
+// WithKafkaProducer 设置 kafka:// 输出使用的 producer，日志器关闭时不会关闭 producer；kafka:// 输出需要导入 sinks/kafka 包
+func WithKafkaProducer(producer output_config.KafkaProducer) Option {
+	return func(c *log_config.LogConfig) {
+		c.KafkaProducer = producer
+	}
+}
+
+// WithHooks 注册日志写入前的钩子，可以修改或丢弃日志
+func WithHooks(hooks ...hook.Hook) Option {
+	return func(c *log_config.LogConfig) {
+		c.Hooks = append(c.Hooks, hooks...)
+	}
+}
+
+// WithPostHooks 注册日志写入后的钩子
+func WithPostHooks(hooks ...hook.PostHook) Option {
+	return func(c *log_config.LogConfig) {
+		c.PostHooks = append(c.PostHooks, hooks...)
+	}
+}
+
+// WithDedup 合并连续重复的日志，window 为两条相同日志的最大间隔，0 表示不合并
+func WithDedup(window time.Duration) Option {
+	return func(c *log_config.LogConfig) {
+		c.DedupWindow = window
+	}
+}
+
+// WithDedupIgnoreFields 设置判断重复日志时额外忽略的字段，trace_id、span_id、trace_flags 总是被忽略
+func WithDedupIgnoreFields(keys ...string) Option {
+	return func(c *log_config.LogConfig) {
+		c.DedupIgnoreFields = append(c.DedupIgnoreFields, keys...)
+	}
+}
+
+// WithRePanic 设置 Recover 记录panic后是否重新panic
+func WithRePanic(rePanic bool) Option {
+	return func(c *log_config.LogConfig) {
+		c.RePanic = rePanic
+	}
+}
+
+// WithFlushOnSignal 设置收到 SIGTERM/SIGINT 时是否在退出前刷新日志及span
+func WithFlushOnSignal(enable bool) Option {
+	return func(c *log_config.LogConfig) {
+		c.FlushOnSignal = enable
+	}
+}
+
+// WithSignalHandled 声明程序自身也监听了 SIGTERM/SIGINT，FlushOnSignal 刷新后不重新发送信号
+func WithSignalHandled(handled bool) Option {
+	return func(c *log_config.LogConfig) {
+		c.SignalHandled = handled
+	}
+}
+
 func WithServiceName(name string) Option {
 	return func(c *log_config.LogConfig) {
 		c.ServiceName = name
@@ -72,20 +142,231 @@ func WithTracing(
 ) Option {
 	return func(c *log_config.LogConfig) {
 		if c.TracerConfig == nil {
-			c.TracerConfig = &tracer_config.DefaultTracerConfig
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
 		}
 
 		c.TracerConfig.Enable = enable
 		c.TracerConfig.CollectorEndpoint = endpoint
+		c.TracerConfig.Compression = compression
 	}
 }
 
 func WithContextHandler(key string, handler tracer_config.ContextHandler) Option {
 	return func(c *log_config.LogConfig) {
 		if c.TracerConfig == nil {
-			c.TracerConfig = &tracer_config.DefaultTracerConfig
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+		if c.TracerConfig.ContextHandlers == nil {
+			c.TracerConfig.ContextHandlers = make(map[string]tracer_config.ContextHandler)
 		}
 
 		c.TracerConfig.ContextHandlers[key] = handler
 	}
 }
+
+// WithBaggageAllowList 设置自动记录到日志的baggage key，为空表示全部记录
+func WithBaggageAllowList(keys ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.BaggageAllowList = keys
+	}
+}
+
+// WithBaggageDenyList 设置不记录到日志的baggage key
+func WithBaggageDenyList(keys ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.BaggageDenyList = keys
+	}
+}
+
+// WithGenerateTraceID 设置请求未携带trace信息时是否生成trace_id，便于未接入collector的服务关联日志；开启导出时由根span生成，不受此设置影响
+func WithGenerateTraceID(generate bool) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.GenerateTraceID = generate
+	}
+}
+
+// WithProtocol 设置otlp导出协议
+func WithProtocol(protocol tracer_config.Protocol) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.Protocol = protocol
+	}
+}
+
+// WithExporterHeaders 设置otlp导出时附带的header
+func WithExporterHeaders(headers map[string]string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.Headers = headers
+	}
+}
+
+// WithSampler 设置采样策略及参数
+func WithSampler(sampler tracer_config.Sampler, arg float64) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.Sampler = sampler
+		c.TracerConfig.SamplerArg = arg
+	}
+}
+
+// WithPropagators 设置传播协议：tracecontext、baggage、b3、b3multi、none
+func WithPropagators(propagators ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.TracerConfig == nil {
+			c.TracerConfig = tracer_config.DefaultTracerConfig.Clone()
+		}
+
+		c.TracerConfig.Propagators = propagators
+	}
+}
+
+// WithRedactKeys 按字段key脱敏，支持通配符，如 password、*token*
+func WithRedactKeys(keys ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.RedactConfig == nil {
+			c.RedactConfig = &redact_config.RedactConfig{}
+		}
+
+		c.RedactConfig.Keys = append(c.RedactConfig.Keys, keys...)
+	}
+}
+
+// WithRedactPatterns 按正则对字符串字段值及日志消息脱敏
+func WithRedactPatterns(patterns ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.RedactConfig == nil {
+			c.RedactConfig = &redact_config.RedactConfig{}
+		}
+
+		c.RedactConfig.Patterns = append(c.RedactConfig.Patterns, patterns...)
+	}
+}
+
+// WithRedactBuiltins 启用内置的脱敏规则，如 redact_config.Email、redact_config.Phone
+func WithRedactBuiltins(names ...string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.RedactConfig == nil {
+			c.RedactConfig = &redact_config.RedactConfig{}
+		}
+
+		c.RedactConfig.Builtins = append(c.RedactConfig.Builtins, names...)
+	}
+}
+
+// WithRedactStrategy 设置脱敏方式，keep 为 truncate 时保留的字符数
+func WithRedactStrategy(strategy redact_config.Strategy, keep int) Option {
+	return func(c *log_config.LogConfig) {
+		if c.RedactConfig == nil {
+			c.RedactConfig = &redact_config.RedactConfig{}
+		}
+
+		c.RedactConfig.Strategy = strategy
+		c.RedactConfig.Keep = keep
+	}
+}
+
+// WithRedactHashKey 设置 hash 脱敏方式使用的密钥
+func WithRedactHashKey(key string) Option {
+	return func(c *log_config.LogConfig) {
+		if c.RedactConfig == nil {
+			c.RedactConfig = &redact_config.RedactConfig{}
+		}
+
+		c.RedactConfig.HashKey = key
+	}
+}
+
+// WithSampling 开启日志采样：每个 interval 内相同级别和消息的日志先输出 first 条，之后每 thereafter 条输出一条
+func WithSampling(interval time.Duration, first, thereafter int) Option {
+	return func(c *log_config.LogConfig) {
+		if c.SamplingConfig == nil {
+			c.SamplingConfig = &sampling_config.SamplingConfig{}
+		}
+
+		c.SamplingConfig.Interval = interval
+		c.SamplingConfig.First = first
+		c.SamplingConfig.Thereafter = thereafter
+	}
+}
+
+// WithLevelSampling 设置指定级别的采样参数，first 为0时该级别不采样
+func WithLevelSampling(level log_level.Level, first, thereafter int) Option {
+	return func(c *log_config.LogConfig) {
+		if c.SamplingConfig == nil {
+			c.SamplingConfig = &sampling_config.SamplingConfig{}
+		}
+		if c.SamplingConfig.Levels == nil {
+			c.SamplingConfig.Levels = make(map[log_level.Level]sampling_config.LevelSampling)
+		}
+
+		c.SamplingConfig.Levels[level] = sampling_config.LevelSampling{First: first, Thereafter: thereafter}
+	}
+}
+
+// WithSamplingExemptErrors 设置 Error 及以上级别的日志是否不参与采样
+func WithSamplingExemptErrors(exempt bool) Option {
+	return func(c *log_config.LogConfig) {
+		if c.SamplingConfig == nil {
+			c.SamplingConfig = &sampling_config.SamplingConfig{}
+		}
+
+		c.SamplingConfig.ExemptErrors = exempt
+	}
+}
+
+// WithSamplingSummaryInterval 设置输出被采样丢弃日志数量汇总的周期
+func WithSamplingSummaryInterval(interval time.Duration) Option {
+	return func(c *log_config.LogConfig) {
+		if c.SamplingConfig == nil {
+			c.SamplingConfig = &sampling_config.SamplingConfig{}
+		}
+
+		c.SamplingConfig.SummaryInterval = interval
+	}
+}
+
+// WithAsync 开启异步写入，每个输出使用 bufferSize 条日志的缓冲区，最长 flushInterval 写入一次，0 表示使用默认值
+func WithAsync(bufferSize int, flushInterval time.Duration) Option {
+	return func(c *log_config.LogConfig) {
+		if c.AsyncConfig == nil {
+			c.AsyncConfig = &async_config.AsyncConfig{}
+		}
+
+		c.AsyncConfig.BufferSize = bufferSize
+		c.AsyncConfig.FlushInterval = flushInterval
+	}
+}
+
+// WithAsyncPolicy 设置异步写入缓冲区满时的处理方式，dropLevel 仅对 DropBelowLevel 生效
+func WithAsyncPolicy(policy async_config.Policy, dropLevel log_level.Level) Option {
+	return func(c *log_config.LogConfig) {
+		if c.AsyncConfig == nil {
+			c.AsyncConfig = &async_config.AsyncConfig{}
+		}
+
+		c.AsyncConfig.Policy = policy
+		c.AsyncConfig.DropLevel = &dropLevel
+	}
+}
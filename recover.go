@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/everfir/logger-go/internal/tracer"
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recover 捕获panic并记录带堆栈的日志，同时将当前span标记为错误
+// 必须直接通过 defer 调用：defer logger.Recover(ctx)
+// 配置 RePanic 时记录FATAL级别日志并刷新后重新panic，否则记录ERROR级别日志并吞掉panic
+func Recover(ctx context.Context, fields ...field.Field) {
+	if r := recover(); r != nil {
+		handlePanic(ctx, r, fields...)
+	}
+}
+
+// Go 启动一个捕获panic的goroutine，panic的处理方式与 Recover 一致
+func Go(ctx context.Context, fn func(ctx context.Context), fields ...field.Field) {
+	go func() {
+		defer Recover(ctx, fields...)
+		fn(ctx)
+	}()
+}
+
+// handlePanic 记录panic信息，按配置决定是否重新panic
+func handlePanic(ctx context.Context, r interface{}, fields ...field.Field) {
+	caller := panicFrame()
+	stack := debug.Stack()
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+
+	// 将当前span标记为错误并记录exception事件
+	if span := tracer.SpanFromContext(ctx); span.IsRecording() {
+		span.RecordError(err, trace.WithStackTrace(true))
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	fields = append(fields,
+		field.String("panic", err.Error()),
+		field.Int64("goroutine_id", goroutineID(stack)),
+		field.String("stacktrace", string(stack)),
+	)
+
+	l := acquire()
+	rePanic := l.config.RePanic
+	l.release()
+
+	// 调用位置使用发生panic的位置，而不是 Recover
+	if !rePanic {
+		write(ctx, log_level.ErrorLevel, false, caller, "[Logger] panic recovered", fields...)
+		return
+	}
+
+	write(ctx, log_level.FatalLevel, false, caller, "[Logger] panic", fields...)
+	flushCtx, cancel := context.WithTimeout(context.Background(), fatalFlushTimeout)
+	_ = flush(flushCtx)
+	cancel()
+	panic(r)
+}
+
+// panicFrame 返回发生panic的位置：runtime.gopanic 之后第一个不在 runtime 包中的调用方，
+// 空指针、越界等运行时错误经过 runtime.sigpanic 等函数，同样跳过；找不到时返回 nil
+func panicFrame() *runtime.Frame {
+	var pcs [32]uintptr
+	// 跳过 runtime.Callers 及 panicFrame
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return &frame
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// flush 刷新日志输出并导出已结束的span
+func flush(ctx context.Context) error {
+	l := acquire()
+	defer l.release()
+
+	err := l.Logger.Sync()
+	if l.Tracer != nil {
+		if flushErr := l.Tracer.Flush(ctx); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+	return err
+}
+
+// goroutineID 从堆栈头部 "goroutine 123 [running]:" 中解析goroutine id
+func goroutineID(stack []byte) int64 {
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i > 0 {
+		if id, err := strconv.ParseInt(string(stack[:i]), 10, 64); err == nil {
+			return id
+		}
+	}
+	return 0
+}
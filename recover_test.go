@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/field"
+	"go.opentelemetry.io/otel/codes"
+	trace_sdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// panicLine 返回调用方的下一行，即紧随其后的panic所在的行
+func panicLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file[strings.LastIndex(file, "/")+1:], line+1)
+}
+
+func checkPanicEntry(t *testing.T, entry map[string]interface{}, level, msg, line string) {
+	t.Helper()
+	if entry["level"] != level || entry["msg"] != msg {
+		t.Errorf("entry: %v %v", entry["level"], entry["msg"])
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasSuffix(caller, "/"+line) {
+		t.Errorf("caller: got %q, want %s", caller, line)
+	}
+	if id, _ := entry["goroutine_id"].(float64); id <= 0 {
+		t.Errorf("goroutine_id: %v", entry["goroutine_id"])
+	}
+	if stack, _ := entry["stacktrace"].(string); !strings.Contains(stack, "recover_test.go") {
+		t.Errorf("stacktrace: %q", stack)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"))
+
+	var line string
+	func() {
+		defer Recover(context.Background(), field.String("job", "sync"))
+		line = panicLine()
+		panic("boom")
+	}()
+
+	// 运行时错误同样使用发生panic的位置
+	var runtimeLine string
+	func() {
+		defer Recover(context.Background())
+		var m map[string]int
+		runtimeLine = panicLine()
+		m["a"] = 1
+	}()
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	checkPanicEntry(t, entries[0], "ERROR", "[Logger] panic recovered", line)
+	if entries[0]["panic"] != "boom" || entries[0]["job"] != "sync" {
+		t.Errorf("fields: %v", entries[0])
+	}
+	checkPanicEntry(t, entries[1], "ERROR", "[Logger] panic recovered", runtimeLine)
+	if panicMsg, _ := entries[1]["panic"].(string); !strings.Contains(panicMsg, "nil map") {
+		t.Errorf("panic: %q", panicMsg)
+	}
+}
+
+func TestGoRecovers(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"))
+
+	lines := make(chan string, 1)
+	Go(context.Background(), func(ctx context.Context) {
+		lines <- panicLine()
+		panic(errors.New("in goroutine"))
+	})
+	line := <-lines
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(readLog(t, dir, "app.log"), "in goroutine") {
+		if time.Now().After(deadline) {
+			t.Fatal("panic in goroutine not logged")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	entries := readEntries(t, dir, "app.log")
+	checkPanicEntry(t, entries[0], "ERROR", "[Logger] panic recovered", line)
+}
+
+func TestRecoverRePanic(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithRePanic(true))
+
+	var line string
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		defer Recover(context.Background())
+		line = panicLine()
+		panic("again")
+	}()
+	if recovered != "again" {
+		t.Fatalf("re-panicked with %v", recovered)
+	}
+
+	// 重新panic前已刷新输出
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	checkPanicEntry(t, entries[0], "FATAL", "[Logger] panic", line)
+}
+
+func TestRecoverMarksSpan(t *testing.T) {
+	swapForTest(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := trace_sdk.NewTracerProvider(trace_sdk.WithSpanProcessor(recorder))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "job")
+	func() {
+		defer Recover(ctx)
+		panic("span failed")
+	}()
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans", len(spans))
+	}
+	status := spans[0].Status()
+	if status.Code != codes.Error || status.Description != "span failed" {
+		t.Errorf("status: %+v", status)
+	}
+	var exception bool
+	for _, event := range spans[0].Events() {
+		exception = exception || event.Name == "exception"
+	}
+	if !exception {
+		t.Error("exception event not recorded")
+	}
+}
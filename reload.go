@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher 监听配置文件变化并热加载日志配置
+// 同时使用 fsnotify 监听目录事件和定时轮询，兼容 ConfigMap 通过符号链接替换文件的方式
+type watcher struct {
+	path    string
+	options []Option
+	sum     [sha256.Size]byte
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var (
+	watcherMu      sync.Mutex
+	currentWatcher *watcher
+)
+
+// startWatcher 开始监听配置文件，data 为当前已生效的文件内容
+func startWatcher(path string, data []byte, interval time.Duration, options []Option) {
+	w := &watcher{
+		path:    path,
+		options: options,
+		sum:     sha256.Sum256(data),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+	if currentWatcher != nil {
+		close(currentWatcher.stop)
+		<-currentWatcher.done
+	}
+	currentWatcher = w
+	go w.run(interval)
+}
+
+// stopWatcher 停止当前的配置文件监听
+func stopWatcher() {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+	if currentWatcher == nil {
+		return
+	}
+	close(currentWatcher.stop)
+	<-currentWatcher.done
+	currentWatcher = nil
+}
+
+func (w *watcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if fw, err := fsnotify.NewWatcher(); err == nil {
+		defer fw.Close()
+		if err = fw.Add(filepath.Dir(w.path)); err == nil {
+			events, errs = fw.Events, fw.Errors
+		}
+	}
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+			continue
+		}
+
+		next, ok := w.check()
+		if !ok {
+			continue
+		}
+		if next <= 0 {
+			Info(context.TODO(), "[Logger] config watcher stopped", field.String("path", w.path))
+			return
+		}
+		if next != interval {
+			interval = next
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// check 检查配置文件内容是否变化，变化时重新加载
+// 返回新配置中的检查间隔，ok 为 false 表示未重新加载
+func (w *watcher) check() (interval time.Duration, ok bool) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		Warn(context.TODO(), "[Logger] read config file failed", field.String("path", w.path), field.Any("error", err))
+		return 0, false
+	}
+
+	sum := sha256.Sum256(data)
+	if sum == w.sum {
+		return 0, false
+	}
+	// 无论加载是否成功都记录，避免对同一份错误配置重复报错
+	w.sum = sum
+
+	config, err := fileConfig(w.path, data, w.options...)
+	if err != nil {
+		Error(context.TODO(), "[Logger] reload config failed", field.String("path", w.path), field.Any("error", err))
+		return 0, false
+	}
+
+	changes, err := reload(config)
+	if err != nil {
+		Error(context.TODO(), "[Logger] reload config failed", field.String("path", w.path), field.Any("error", err))
+		return 0, false
+	}
+
+	Info(context.TODO(), "[Logger] config reloaded", field.String("path", w.path), field.Any("changes", changes))
+	return config.WatchInterval, true
+}
+
+// reload 使用新的配置替换全局日志器，返回配置变更项
+func reload(config *log_config.LogConfig) ([]log_config.Change, error) {
+	prev, err := replace(config)
+	if err != nil {
+		return nil, err
+	}
+	return log_config.Diff(prev, config), nil
+}
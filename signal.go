@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// flushSignals 开启 FlushOnSignal 后，收到这些信号时会在退出前刷新日志及span
+var flushSignals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+
+var (
+	signalMu   sync.Mutex
+	signalStop chan struct{}
+)
+
+// startSignalHandler 收到退出信号时刷新日志及span，不关闭日志器，程序自身的退出逻辑仍可以正常写日志
+// 之后重新发送信号使进程按原有方式退出；配置了 SignalHandled 时由程序自身处理信号，不重新发送
+func startSignalHandler() {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if signalStop != nil {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, flushSignals...)
+	stop := make(chan struct{})
+	signalStop = stop
+
+	go func() {
+		var sig os.Signal
+		select {
+		case <-stop:
+			signal.Stop(ch)
+			return
+		case sig = <-ch:
+			signal.Stop(ch)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		if err := flush(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "[Logger] flush on signal %s failed: %s\n", sig, err)
+		}
+		cancel()
+
+		l := acquire()
+		handled := l.config.SignalHandled
+		l.release()
+		if handled {
+			return
+		}
+
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Signal(sig)
+		}
+	}()
+}
+
+// stopSignalHandler 停止监听退出信号，不等待正在进行的关闭
+func stopSignalHandler() {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if signalStop == nil {
+		return
+	}
+	close(signalStop)
+	signalStop = nil
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitForLog 等待日志文件中出现 text
+func waitForLog(t *testing.T, dir, name, text string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(readLog(t, dir, name), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%q not written to %s", text, name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// signalCount 统计一段时间内收到的信号数
+func signalCount(ch <-chan os.Signal, d time.Duration) int {
+	n := 0
+	timeout := time.After(d)
+	for {
+		select {
+		case <-ch:
+			n++
+		case <-timeout:
+			return n
+		}
+	}
+}
+
+func TestFlushOnSignal(t *testing.T) {
+	t.Setenv("OTEL_SDK_DISABLED", "true")
+
+	for _, handled := range []bool{true, false} {
+		dir := chdirTemp(t)
+
+		// 模拟程序自身的信号处理，同时避免重新发送的信号结束测试进程
+		app := make(chan os.Signal, 4)
+		signal.Notify(app, syscall.SIGTERM)
+
+		if err := Init(WithOutputFiles("app.log"), WithAsync(1024, time.Hour), WithFlushOnSignal(true), WithSignalHandled(handled)); err != nil {
+			t.Fatal(err)
+		}
+		Info(context.Background(), "before signal")
+
+		if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+			t.Fatal(err)
+		}
+		waitForLog(t, dir, "app.log", "before signal")
+
+		// 程序自身处理信号时不重新发送，否则程序会再收到一次
+		want := 1
+		if !handled {
+			want = 2
+		}
+		if got := signalCount(app, 300*time.Millisecond); got != want {
+			t.Errorf("handled=%v: app received %d signals, want %d", handled, got, want)
+		}
+		signal.Stop(app)
+
+		// 刷新后日志器仍然可用
+		Info(context.Background(), "after signal")
+		if err := Sync(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(readLog(t, dir, "app.log"), "after signal") {
+			t.Errorf("handled=%v: logger closed by the signal handler", handled)
+		}
+		if err := Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
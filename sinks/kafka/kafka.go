@@ -0,0 +1,348 @@
+// Package kafka 提供 kafka://topic 输出，导入后注册：
+//
+//	import _ "github.com/everfir/logger-go/sinks/kafka"
+//
+// 内置的 producer 基于 franz-go，未导入该包的程序不会链接 franz-go
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/everfir/logger-go/internal/logger"
+	"github.com/everfir/logger-go/structs/log_config"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	defaultKafkaKeyField      = "trace_id"
+	defaultKafkaBatchSize     = 500
+	defaultKafkaBatchBytes    = 1 << 20
+	defaultKafkaFlushInterval = time.Second
+	defaultKafkaTimeout       = 10 * time.Second
+	defaultKafkaRetries       = 3
+
+	kafkaMaxBackoff = 30 * time.Second
+)
+
+func init() {
+	logger.RegisterSink("kafka", func(output output_config.OutputConfig, config *log_config.LogConfig, openFile func(name string) (io.WriteCloser, error)) (logger.Sink, error) {
+		return newKafkaSink(output.Target, output.Kafka, config.KafkaProducer, openFile)
+	})
+}
+
+// kafkaSink 将日志按字段分区，批量交给 producer 发送，发送失败的消息写入本地文件
+type kafkaSink struct {
+	topic    string
+	config   output_config.KafkaConfig
+	producer output_config.KafkaProducer
+	client   *kgo.Client // 内置 producer 的客户端，关闭输出时一起关闭
+	fallback io.WriteCloser
+
+	mu      sync.Mutex
+	pending []output_config.KafkaMessage
+	size    int
+	sending sync.Mutex // 保证批次按顺序发送
+	stats   output_config.Stats
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// newKafkaSink 创建 Kafka 输出，fallback 在发送失败时写入
+func newKafkaSink(target string, config *output_config.KafkaConfig, producer output_config.KafkaProducer, fallback func(name string) (io.WriteCloser, error)) (*kafkaSink, error) {
+	topic := strings.TrimPrefix(target, "kafka://")
+	if topic == "" || strings.ContainsAny(topic, "/?") {
+		return nil, fmt.Errorf("invalid kafka target %q, expect kafka://topic", target)
+	}
+
+	s := &kafkaSink{
+		topic: topic,
+		kick:  make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if config != nil {
+		s.config = *config.Clone()
+	}
+	s.fixDefault()
+
+	var err error
+	switch {
+	case s.config.Producer != nil:
+		s.producer = s.config.Producer
+	case len(s.config.Brokers) > 0:
+		if s.client, err = newKafkaClient(&s.config); err != nil {
+			return nil, fmt.Errorf("create kafka client for %s failed: %w", target, err)
+		}
+		s.producer = kgoProducer{s.client}
+	case producer != nil:
+		s.producer = producer
+	default:
+		return nil, fmt.Errorf("kafka output %s requires brokers or a producer set with logger.WithKafkaProducer", target)
+	}
+
+	if s.fallback, err = fallback(s.config.Fallback); err != nil {
+		if s.client != nil {
+			s.client.Close()
+		}
+		return nil, fmt.Errorf("create kafka fallback file failed: %w", err)
+	}
+	s.stats.Target = target
+
+	go s.run()
+	return s, nil
+}
+
+func (s *kafkaSink) fixDefault() {
+	c := &s.config
+	if c.KeyField == "" {
+		c.KeyField = defaultKafkaKeyField
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultKafkaBatchSize
+	}
+	if c.BatchBytes == 0 {
+		c.BatchBytes = defaultKafkaBatchBytes
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultKafkaFlushInterval
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultKafkaTimeout
+	}
+	if c.Retries == 0 {
+		c.Retries = defaultKafkaRetries
+	} else if c.Retries < 0 {
+		c.Retries = 0
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = c.BatchSize * 10
+	}
+	if c.Fallback == "" {
+		c.Fallback = "kafka-" + s.topic + ".log"
+	}
+}
+
+// Write 加入待发送的消息，缓冲区满时直接写入本地文件
+func (s *kafkaSink) Write(entry logger.SinkEntry) error {
+	msg := output_config.KafkaMessage{
+		Topic: s.topic,
+		Value: bytes.TrimRight(append([]byte(nil), entry.Encoded...), "\n"),
+		Time:  entry.Time,
+	}
+	if key, ok := entry.Field(s.config.KeyField); ok && key != "" {
+		msg.Key = []byte(key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= s.config.BufferSize {
+		s.fallbackLocked([]output_config.KafkaMessage{msg})
+		return nil
+	}
+
+	s.pending = append(s.pending, msg)
+	s.size += len(msg.Value)
+	if len(s.pending) >= s.config.BatchSize || s.size >= s.config.BatchBytes {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *kafkaSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.kick:
+		}
+		s.flush(true)
+	}
+}
+
+// flush 发送所有待发送的消息，retry 为 false 时每个批次只尝试一次
+func (s *kafkaSink) flush(retry bool) {
+	s.sending.Lock()
+	defer s.sending.Unlock()
+
+	for {
+		batch := s.takeBatch()
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.send(batch, retry); err != nil {
+			s.mu.Lock()
+			s.fallbackLocked(batch)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// takeBatch 取出不超过批次大小的消息
+func (s *kafkaSink) takeBatch() []output_config.KafkaMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, size := 0, 0
+	for n < len(s.pending) && n < s.config.BatchSize && (n == 0 || size+len(s.pending[n].Value) <= s.config.BatchBytes) {
+		size += len(s.pending[n].Value)
+		n++
+	}
+	batch := s.pending[:n:n]
+	s.pending = s.pending[n:]
+	s.size -= size
+	return batch
+}
+
+// send 发送一个批次，失败时按指数退避重试
+func (s *kafkaSink) send(batch []output_config.KafkaMessage, retry bool) (err error) {
+	attempts := 1
+	if retry {
+		attempts += s.config.Retries
+	}
+
+	backoff := 100 * time.Millisecond
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-s.stop:
+				return err
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > kafkaMaxBackoff {
+				backoff = kafkaMaxBackoff
+			}
+		}
+
+		if err = s.produce(batch); err == nil {
+			s.mu.Lock()
+			s.stats.Written += uint64(len(batch))
+			s.stats.Connected = true
+			s.mu.Unlock()
+			return nil
+		}
+
+		s.mu.Lock()
+		s.stats.Connected = false
+		s.stats.LastError, s.stats.LastErrorTime = err.Error(), time.Now()
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// produce 调用 producer 发送一次，producer panic 时作为发送失败处理
+func (s *kafkaSink) produce(batch []output_config.KafkaMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("kafka producer panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	return s.producer.Produce(ctx, batch)
+}
+
+// fallbackLocked 将消息写入本地文件，调用时需持有 mu
+func (s *kafkaSink) fallbackLocked(batch []output_config.KafkaMessage) {
+	var buf bytes.Buffer
+	for _, msg := range batch {
+		buf.Write(msg.Value)
+		buf.WriteByte('\n')
+	}
+	if _, err := s.fallback.Write(buf.Bytes()); err != nil {
+		s.stats.Dropped += uint64(len(batch))
+		s.stats.LastError, s.stats.LastErrorTime = err.Error(), time.Now()
+		return
+	}
+	s.stats.Spilled += uint64(len(batch))
+	s.stats.SpillBytes += int64(buf.Len())
+}
+
+// Stats 返回输出的运行状态
+func (s *kafkaSink) Stats() output_config.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Sync 立即发送待发送的消息，每个批次只尝试一次，失败的写入本地文件
+func (s *kafkaSink) Sync() error {
+	s.flush(false)
+	return nil
+}
+
+// Close 停止后台发送，发送剩余的消息并关闭本地文件，只关闭内置的 producer
+func (s *kafkaSink) Close() (err error) {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.flush(false)
+		if s.client != nil {
+			s.client.Close()
+		}
+		err = s.fallback.Close()
+	})
+	return err
+}
+
+// newKafkaClient 创建内置 producer 使用的 franz-go 客户端，消息由 kafkaSink 攒批，客户端不再等待
+// 有 key 的消息按 key 的 murmur2 哈希选择分区，与 Java 客户端一致
+func newKafkaClient(config *output_config.KafkaConfig) (*kgo.Client, error) {
+	var codec kgo.CompressionCodec
+	switch config.Compression {
+	case "", "none":
+		codec = kgo.NoCompression()
+	case "gzip":
+		codec = kgo.GzipCompression()
+	case "snappy":
+		codec = kgo.SnappyCompression()
+	case "lz4":
+		codec = kgo.Lz4Compression()
+	case "zstd":
+		codec = kgo.ZstdCompression()
+	default:
+		return nil, fmt.Errorf("unsupported kafka compression %q", config.Compression)
+	}
+
+	return kgo.NewClient(
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.ProducerBatchCompression(codec),
+		kgo.ProducerLinger(0),
+		// 单次发送由 kafkaSink 按 Timeout 取消，这里只作为兜底，franz-go 要求不小于 1s
+		kgo.RecordDeliveryTimeout(max(config.Timeout, time.Second)),
+	)
+}
+
+// kgoProducer 基于 franz-go 的内置 producer
+type kgoProducer struct {
+	client *kgo.Client
+}
+
+// Produce 同步发送一批消息，任意一条失败时整批视为失败
+func (p kgoProducer) Produce(ctx context.Context, messages []output_config.KafkaMessage) error {
+	records := make([]*kgo.Record, 0, len(messages))
+	for _, msg := range messages {
+		records = append(records, &kgo.Record{Topic: msg.Topic, Key: msg.Key, Value: msg.Value, Timestamp: msg.Time})
+	}
+	return p.client.ProduceSync(ctx, records...).FirstErr()
+}
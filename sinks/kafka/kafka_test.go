@@ -0,0 +1,273 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/internal/logger"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// nopBuffer 作为 fallback 文件的内存缓冲区
+type nopBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *nopBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *nopBuffer) Close() error { return nil }
+
+func (b *nopBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestKafkaSink(t *testing.T, config output_config.KafkaConfig, producer output_config.KafkaProducer) (*kafkaSink, *nopBuffer) {
+	t.Helper()
+	if config.FlushInterval == 0 {
+		config.FlushInterval = time.Hour
+	}
+	fallback := &nopBuffer{}
+	s, err := newKafkaSink("kafka://audit", &config, producer, func(string) (io.WriteCloser, error) {
+		return fallback, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, fallback
+}
+
+func writeKafkaEntry(t *testing.T, s *kafkaSink, msg string, fields ...zapcore.Field) {
+	t.Helper()
+	entry := logger.SinkEntry{
+		Entry:   zapcore.Entry{Message: msg, Time: time.Now()},
+		Fields:  fields,
+		Encoded: []byte(fmt.Sprintf("{\"msg\":%q}\n", msg)),
+	}
+	if err := s.Write(entry); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recordingProducer 记录每次发送的批次
+type recordingProducer struct {
+	mu      sync.Mutex
+	batches [][]output_config.KafkaMessage
+}
+
+func (p *recordingProducer) Produce(_ context.Context, messages []output_config.KafkaMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = append(p.batches, append([]output_config.KafkaMessage(nil), messages...))
+	return nil
+}
+
+func (p *recordingProducer) sizes() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sizes := make([]int, 0, len(p.batches))
+	for _, batch := range p.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestKafkaSinkBatching(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		producer := &recordingProducer{}
+		s, _ := newTestKafkaSink(t, output_config.KafkaConfig{BatchSize: 2}, producer)
+		for i := 0; i < 5; i++ {
+			writeKafkaEntry(t, s, fmt.Sprint(i))
+		}
+		_ = s.Close()
+		total := 0
+		for _, n := range producer.sizes() {
+			if n > 2 {
+				t.Errorf("batch of %d exceeds batch size", n)
+			}
+			total += n
+		}
+		if total != 5 {
+			t.Errorf("got %d messages, want 5", total)
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		producer := &recordingProducer{}
+		// 每条消息 11 字节，每批最多 2 条
+		s, _ := newTestKafkaSink(t, output_config.KafkaConfig{BatchBytes: 25}, producer)
+		for i := 0; i < 5; i++ {
+			writeKafkaEntry(t, s, fmt.Sprint(i))
+		}
+		s.flush(false)
+		if got := fmt.Sprint(producer.sizes()); got != "[2 2 1]" {
+			t.Errorf("batches: got %s, want [2 2 1]", got)
+		}
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		producer := &recordingProducer{}
+		s, _ := newTestKafkaSink(t, output_config.KafkaConfig{FlushInterval: 20 * time.Millisecond}, producer)
+		writeKafkaEntry(t, s, "a")
+		waitFor(t, func() bool { return len(producer.sizes()) == 1 })
+	})
+}
+
+// 内置 producer 按 key_field 字段的值作为 key，相同 key 的消息进入同一分区
+func TestKafkaSinkPartitionsByField(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(3, "audit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	s, fallback := newTestKafkaSink(t, output_config.KafkaConfig{Brokers: cluster.ListenAddrs(), KeyField: "user"}, nil)
+	users := []string{"alice", "bob", "carol", "dave"}
+	for i := 0; i < 20; i++ {
+		writeKafkaEntry(t, s, fmt.Sprint(i), zap.String("user", users[i%len(users)]))
+	}
+	writeKafkaEntry(t, s, "anonymous")
+	_ = s.Close()
+
+	if stats := s.Stats(); stats.Written != 21 || stats.Spilled != 0 {
+		t.Fatalf("stats: written %d spilled %d, fallback %q", stats.Written, stats.Spilled, fallback.String())
+	}
+
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics("audit"),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	partitions := make(map[string]int32)
+	used := make(map[int32]bool)
+	for received := 0; received < 21; {
+		fetches := consumer.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			t.Fatalf("received %d messages: %v", received, err)
+		}
+		fetches.EachRecord(func(r *kgo.Record) {
+			received++
+			if !strings.Contains(string(r.Value), `"msg":`) || bytes.HasSuffix(r.Value, []byte("\n")) {
+				t.Errorf("value: %q", r.Value)
+			}
+			if r.Key == nil {
+				if string(r.Value) != `{"msg":"anonymous"}` {
+					t.Errorf("message without key: %q", r.Value)
+				}
+				return
+			}
+			key := string(r.Key)
+			if p, ok := partitions[key]; ok && p != r.Partition {
+				t.Errorf("key %s in partitions %d and %d", key, p, r.Partition)
+			}
+			partitions[key] = r.Partition
+			used[r.Partition] = true
+		})
+	}
+	if len(partitions) != len(users) {
+		t.Errorf("keys: got %v", partitions)
+	}
+	if len(used) < 2 {
+		t.Errorf("all keys in one partition: %v", partitions)
+	}
+}
+
+// 发送失败的消息写入 fallback 文件
+func TestKafkaSinkFallbackOnDeliveryFailure(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "audit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close()
+
+	// 开启后 broker 拒绝所有写入
+	var failing atomic.Bool
+	cluster.ControlKey(int16(kmsg.Produce), func(req kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.KeepControl()
+		if !failing.Load() {
+			return nil, nil, false
+		}
+		produce := req.(*kmsg.ProduceRequest)
+		resp := produce.ResponseKind().(*kmsg.ProduceResponse)
+		for _, topic := range produce.Topics {
+			rt := kmsg.NewProduceResponseTopic()
+			rt.Topic = topic.Topic
+			for _, partition := range topic.Partitions {
+				rp := kmsg.NewProduceResponseTopicPartition()
+				rp.Partition = partition.Partition
+				rp.ErrorCode = kerr.InvalidRecord.Code
+				rt.Partitions = append(rt.Partitions, rp)
+			}
+			resp.Topics = append(resp.Topics, rt)
+		}
+		return resp, nil, true
+	})
+
+	s, fallback := newTestKafkaSink(t, output_config.KafkaConfig{Brokers: cluster.ListenAddrs(), Retries: -1, Timeout: time.Second}, nil)
+	writeKafkaEntry(t, s, "sent")
+	s.flush(false)
+	if stats := s.Stats(); stats.Written != 1 {
+		t.Fatalf("written %d before failure: %s", stats.Written, stats.LastError)
+	}
+
+	failing.Store(true)
+	writeKafkaEntry(t, s, "lost-1")
+	writeKafkaEntry(t, s, "lost-2")
+	s.flush(false)
+
+	stats := s.Stats()
+	if stats.Written != 1 || stats.Spilled != 2 || stats.Connected || stats.LastError == "" {
+		t.Errorf("stats: %+v", stats)
+	}
+	if got := fallback.String(); got != "{\"msg\":\"lost-1\"}\n{\"msg\":\"lost-2\"}\n" {
+		t.Errorf("fallback: got %q", got)
+	}
+}
+
+func TestKafkaSinkRequiresProducer(t *testing.T) {
+	_, err := newKafkaSink("kafka://audit", nil, nil, func(string) (io.WriteCloser, error) {
+		return &nopBuffer{}, nil
+	})
+	if err == nil {
+		t.Fatal("expect error without brokers or producer")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// slogHandler 将 slog 的日志写入全局日志器，与 Info 等函数经过相同的钩子、脱敏、tracing 及输出
+type slogHandler struct {
+	opts   slog.HandlerOptions
+	goas   []groupOrAttrs
+	groups []string // 已打开的 group，用于 ReplaceAttr
+}
+
+// groupOrAttrs WithGroup 或 WithAttrs 的结果，按调用顺序保存
+type groupOrAttrs struct {
+	group  string
+	fields []field.Field
+}
+
+// NewSlogHandler 返回写入全局日志器的 slog.Handler，如 slog.SetDefault(slog.New(logger.NewSlogHandler(nil)))
+// opts 为 nil 时使用全局日志级别；opts.Level 在全局日志级别之上进一步过滤；调用位置始终使用 slog 记录的位置，忽略 AddSource
+// group 输出为嵌套对象，上下文中的 trace 信息与 Info 等函数相同方式提取
+// Enabled 与 Info 等函数使用相同的判断：全局日志级别（各输出级别的下限）或 span 事件的级别，不检查各输出单独的级别，
+// 因此所有输出都会过滤掉的级别也可能返回 true
+func NewSlogHandler(opts *slog.HandlerOptions) slog.Handler {
+	h := &slogHandler{}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.opts.Level != nil && level < h.opts.Level.Level() {
+		return false
+	}
+
+	l := acquire()
+	defer l.release()
+	return l.enabled(fromSlogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var fields []field.Field
+	r.Attrs(func(attr slog.Attr) bool {
+		fields = h.appendAttr(fields, h.groups, attr)
+		return true
+	})
+
+	// 从内向外处理 WithGroup/WithAttrs，空的 group 不输出
+	for i := len(h.goas) - 1; i >= 0; i-- {
+		goa := h.goas[i]
+		if goa.group == "" {
+			fields = append(goa.fields[:len(goa.fields):len(goa.fields)], fields...)
+		} else if len(fields) > 0 {
+			fields = []field.Field{field.Object(goa.group, fields...)}
+		}
+	}
+
+	var caller *runtime.Frame
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		caller = &frame
+	}
+	write(ctx, fromSlogLevel(r.Level), false, caller, r.Message, fields...)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	var fields []field.Field
+	for _, attr := range attrs {
+		fields = h.appendAttr(fields, h.groups, attr)
+	}
+	return h.with(groupOrAttrs{fields: fields})
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	ret := h.with(groupOrAttrs{group: name})
+	ret.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return ret
+}
+
+func (h *slogHandler) with(goa groupOrAttrs) *slogHandler {
+	ret := *h
+	ret.goas = append(h.goas[:len(h.goas):len(h.goas)], goa)
+	return &ret
+}
+
+// appendAttr 将 slog.Attr 转换为字段，group 转换为嵌套对象，key 为空的 group 展开到当前层级
+func (h *slogHandler) appendAttr(fields []field.Field, groups []string, attr slog.Attr) []field.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup && h.opts.ReplaceAttr != nil {
+		attr = h.opts.ReplaceAttr(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	v := attr.Value
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			for _, a := range attrs {
+				fields = h.appendAttr(fields, groups, a)
+			}
+			return fields
+		}
+
+		var nested []field.Field
+		inner := append(groups[:len(groups):len(groups)], attr.Key)
+		for _, a := range attrs {
+			nested = h.appendAttr(nested, inner, a)
+		}
+		if len(nested) == 0 {
+			return fields
+		}
+		return append(fields, field.Object(attr.Key, nested...))
+	case slog.KindString:
+		return append(fields, field.String(attr.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, field.Int64(attr.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, field.Uint64(attr.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, field.Float64(attr.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, field.Bool(attr.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, field.Duration(attr.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, field.Time(attr.Key, v.Time()))
+	default:
+		return append(fields, field.Any(attr.Key, v.Any()))
+	}
+}
+
+// fromSlogLevel 将 slog 级别转换为日志级别，高于 error 的自定义级别作为 FATAL 输出但不退出进程
+func fromSlogLevel(level slog.Level) log_level.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return log_level.DebugLevel
+	case level < slog.LevelWarn:
+		return log_level.InfoLevel
+	case level < slog.LevelError:
+		return log_level.WarnLevel
+	case level <= slog.LevelError:
+		return log_level.ErrorLevel
+	default:
+		return log_level.FatalLevel
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slogEntries 使用 opts 创建 slog.Logger，调用 log 后返回写入的日志
+func slogEntries(t *testing.T, opts *slog.HandlerOptions, log func(*slog.Logger)) []map[string]interface{} {
+	t.Helper()
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithLevel(log_level.DebugLevel))
+
+	log(slog.New(NewSlogHandler(opts)))
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	return readEntries(t, dir, "app.log")
+}
+
+func TestSlogLevels(t *testing.T) {
+	levels := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug - 4, "DEBUG"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelInfo + 2, "INFO"},
+		{slog.LevelWarn, "WARN"},
+		{slog.LevelError, "ERROR"},
+		// 高于 error 的自定义级别作为 FATAL 输出但不退出
+		{slog.LevelError + 4, "FATAL"},
+	}
+	entries := slogEntries(t, nil, func(l *slog.Logger) {
+		for _, tt := range levels {
+			l.Log(context.Background(), tt.level, tt.level.String())
+		}
+	})
+	if len(entries) != len(levels) {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	for i, tt := range levels {
+		if entries[i]["level"] != tt.want || entries[i]["msg"] != tt.level.String() {
+			t.Errorf("%s: got %v, want %s", tt.level, entries[i], tt.want)
+		}
+		checkCaller(t, entries[i], "slog_test.go")
+	}
+}
+
+func TestSlogEnabled(t *testing.T) {
+	swapForTest(t, WithLevel(log_level.WarnLevel))
+
+	ctx := context.Background()
+	h := NewSlogHandler(nil)
+	if h.Enabled(ctx, slog.LevelInfo) || !h.Enabled(ctx, slog.LevelWarn) {
+		t.Error("Enabled does not follow the global level")
+	}
+	// opts.Level 在全局级别之上进一步过滤
+	h = NewSlogHandler(&slog.HandlerOptions{Level: slog.LevelError})
+	if h.Enabled(ctx, slog.LevelWarn) || !h.Enabled(ctx, slog.LevelError) {
+		t.Error("Enabled ignores opts.Level")
+	}
+	h = NewSlogHandler(&slog.HandlerOptions{Level: slog.LevelDebug})
+	if h.Enabled(ctx, slog.LevelInfo) {
+		t.Error("opts.Level lowered the global level")
+	}
+}
+
+func TestSlogGroups(t *testing.T) {
+	entries := slogEntries(t, nil, func(l *slog.Logger) {
+		l.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h").Info("nested", "c", 3)
+		// 空的 group 不输出
+		l.WithGroup("empty").Info("empty group", slog.Group("none"))
+		l.WithGroup("g").Info("empty attrs")
+		// key 为空的 group 展开到当前层级
+		l.Info("inline", slog.Group("", "x", 1), slog.Group("req", "method", "GET"))
+	})
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"a", 1.0},
+		{"g", map[string]interface{}{"b": 2.0, "h": map[string]interface{}{"c": 3.0}}},
+	}
+	for _, tt := range tests {
+		if got := entries[0][tt.key]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("nested %s: got %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	for _, e := range entries[1:3] {
+		for key := range e {
+			if key == "empty" || key == "none" || key == "g" {
+				t.Errorf("%v: empty group %s written", e["msg"], key)
+			}
+		}
+	}
+	if e := entries[3]; e["x"] != 1.0 || !reflect.DeepEqual(e["req"], map[string]interface{}{"method": "GET"}) {
+		t.Errorf("inline: %v", e)
+	}
+}
+
+func TestSlogReplaceAttr(t *testing.T) {
+	var seen []string
+	opts := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		seen = append(seen, strings.Join(append(groups[:len(groups):len(groups)], a.Key), "."))
+		switch a.Key {
+		case "drop":
+			return slog.Attr{}
+		case "secret":
+			return slog.String(a.Key, "***")
+		}
+		return a
+	}}
+	entries := slogEntries(t, opts, func(l *slog.Logger) {
+		l.WithGroup("g").With("secret", "a").Info("replace", "drop", 1, slog.Group("inner", "secret", "b"), "keep", 2)
+	})
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+
+	want := map[string]interface{}{"secret": "***", "inner": map[string]interface{}{"secret": "***"}, "keep": 2.0}
+	if got := entries[0]["g"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// ReplaceAttr 收到属性所在的 group，不对 group 本身调用
+	wantSeen := []string{"g.secret", "g.drop", "g.inner.secret", "g.keep"}
+	if !reflect.DeepEqual(seen, wantSeen) {
+		t.Errorf("ReplaceAttr calls: got %v, want %v", seen, wantSeen)
+	}
+}
+
+func TestSlogTraceContext(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	entries := slogEntries(t, nil, func(l *slog.Logger) {
+		l.InfoContext(ctx, "traced")
+	})
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	if got := entries[0]["trace_id"]; got != sc.TraceID().String() {
+		t.Errorf("trace_id: got %v, want %s", got, sc.TraceID())
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"runtime"
+	"strings"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// RedirectStdLog 将标准库 log 包默认 logger 的输出按 level 写入全局日志器，每次输出为一条日志，返回恢复原输出的函数
+// 会清空 log 的 flags，时间、调用位置由日志器记录；log.Fatal* 及 log.Panic* 仍由 log 包退出或 panic，
+// 退出或 panic 前会调用 Sync 写完异步缓冲区
+func RedirectStdLog(level log_level.Level) (restore func()) {
+	flags, prefix, output := log.Flags(), log.Prefix(), log.Writer()
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{level: level})
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(output)
+	}
+}
+
+// stdLogWriter 将 log 包的每次输出写为一条日志
+type stdLogWriter struct {
+	level log_level.Level
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte{'\n'}))
+
+	// FATAL 级别由 log.Fatal* 自行退出
+	caller, terminal := stdLogCaller()
+	write(context.Background(), w.level, false, caller, msg)
+	if terminal {
+		_ = Sync()
+	}
+	return len(p), nil
+}
+
+// stdLogCaller 返回 log 包之外的第一个调用位置，以及是否由 log.Fatal*、log.Panic* 调用
+func stdLogCaller() (caller *runtime.Frame, terminal bool) {
+	var pcs [16]uintptr
+	// 跳过 runtime.Callers、stdLogCaller 及 Write
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		name, ok := strings.CutPrefix(frame.Function, "log.")
+		if !ok {
+			return &frame, terminal
+		}
+		name = strings.TrimPrefix(name, "(*Logger).")
+		terminal = terminal || strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")
+		if !more {
+			return nil, terminal
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"))
+
+	log.SetPrefix("[std] ")
+	restore := RedirectStdLog(log_level.WarnLevel)
+	log.Printf("hello %d", 1)
+	log.Println("world")
+	restore()
+	if log.Prefix() != "[std] " || log.Flags() != log.LstdFlags {
+		t.Errorf("restore: prefix %q, flags %d", log.Prefix(), log.Flags())
+	}
+	log.SetPrefix("")
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries(t, dir, "app.log")
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries: %v", len(entries), entries)
+	}
+	for i, want := range []string{"[std] hello 1", "[std] world"} {
+		if entries[i]["msg"] != want || entries[i]["level"] != "WARN" {
+			t.Errorf("entry %d: %v", i, entries[i])
+		}
+		checkCaller(t, entries[i], "stdlog_test.go")
+	}
+}
+
+// log.Panic* 在 panic 前写完异步缓冲区
+func TestRedirectStdLogSyncsOnPanic(t *testing.T) {
+	dir := chdirTemp(t)
+	swapForTest(t, WithOutputFiles("app.log"), WithAsync(1024, time.Hour))
+	defer RedirectStdLog(log_level.ErrorLevel)()
+
+	log.Print("buffered")
+	if strings.Contains(readLog(t, dir, "app.log"), "buffered") {
+		t.Fatal("async output written before Sync")
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		log.Panicf("boom %d", 1)
+	}()
+	content := readLog(t, dir, "app.log")
+	if !strings.Contains(content, "buffered") || !strings.Contains(content, "boom 1") {
+		t.Errorf("log.Panic did not sync: %q", content)
+	}
+
+	Info(context.Background(), "after")
+	if strings.Contains(readLog(t, dir, "app.log"), "after") {
+		t.Error("unexpected sync after log.Panic")
+	}
+}
@@ -0,0 +1,64 @@
+package async_config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// Policy 缓冲区满时的处理方式
+type Policy string
+
+const (
+	Block          Policy = "block"            // 阻塞写日志的goroutine直到有空间
+	DropNewest     Policy = "drop_newest"      // 丢弃新写入的日志
+	DropOldest     Policy = "drop_oldest"      // 丢弃缓冲区中最旧的日志
+	DropBelowLevel Policy = "drop_below_level" // 丢弃低于 DropLevel 的新日志，其余阻塞
+)
+
+// AsyncConfig 异步写入配置，每个输出使用独立的有界缓冲区和后台写入goroutine
+type AsyncConfig struct {
+	BufferSize    int           `config:"buffer_size,nonnegative"` // 缓冲区可容纳的日志条数，默认 8192
+	FlushInterval time.Duration `config:"flush_interval"`          // 后台写入的最大间隔，默认 100ms，缓冲区过半时提前写入
+	Policy        Policy        `config:"policy"`                  // 缓冲区满时的处理方式，默认 block
+
+	// DropBelowLevel 策略下可以丢弃的级别上限，低于该级别的日志在缓冲区满时被丢弃，nil 时为 warn
+	// 设置为 debug 时不丢弃任何日志
+	DropLevel *log_level.Level `config:"drop_level"`
+}
+
+// Clone 复制配置
+func (config *AsyncConfig) Clone() *AsyncConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	if config.DropLevel != nil {
+		level := *config.DropLevel
+		ret.DropLevel = &level
+	}
+	return &ret
+}
+
+// Validate 检查异步写入配置
+func (config *AsyncConfig) Validate() error {
+	if config == nil {
+		return nil
+	}
+
+	if config.BufferSize < 0 {
+		return fmt.Errorf("async buffer size must not be negative, got %d", config.BufferSize)
+	}
+	if config.FlushInterval < 0 {
+		return fmt.Errorf("async flush interval must not be negative, got %s", config.FlushInterval)
+	}
+
+	switch config.Policy {
+	case "", Block, DropNewest, DropOldest, DropBelowLevel:
+		return nil
+	default:
+		return fmt.Errorf("unsupported async policy %q", config.Policy)
+	}
+}
@@ -23,6 +23,7 @@ const (
 	TimeType
 	DurationType
 	AnyType
+	ObjectType // 嵌套对象，值为 []Field
 )
 
 // Field 定义日志字段接口
@@ -111,3 +112,8 @@ func Duration(key string, value time.Duration) Field {
 func Any(key string, value interface{}) Field {
 	return baseField{key: key, value: value, typ: AnyType}
 }
+
+// Object 将多个字段作为一个嵌套对象输出，如 slog 的 group
+func Object(key string, fields ...Field) Field {
+	return baseField{key: key, value: fields, typ: ObjectType}
+}
@@ -0,0 +1,39 @@
+package hook
+
+import (
+	"context"
+
+	"github.com/everfir/logger-go/structs/field"
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// Entry 一条日志
+type Entry struct {
+	Context context.Context
+	Level   log_level.Level
+	Message string
+	Fields  []field.Field
+}
+
+// Hook 在日志写入前调用，可以修改 entry 的 Message 和 Fields，返回 false 时丢弃该条日志
+// 仅对达到日志级别的日志调用；调用时持有日志器的读锁，不能在其中调用日志函数
+type Hook interface {
+	Before(entry *Entry) bool
+}
+
+// PostHook 在日志写入后调用，用于统计、告警等副作用，entry 中包含最终写入的字段
+// 调用时已释放日志器的锁，可以在其中调用日志函数，但需要自行避免无限递归
+// Fatal 日志会在写入前调用，因为写入后进程将退出
+type PostHook interface {
+	After(entry Entry)
+}
+
+// HookFunc 函数形式的 Hook
+type HookFunc func(entry *Entry) bool
+
+func (f HookFunc) Before(entry *Entry) bool { return f(entry) }
+
+// PostHookFunc 函数形式的 PostHook
+type PostHookFunc func(entry Entry)
+
+func (f PostHookFunc) After(entry Entry) { f(entry) }
@@ -0,0 +1,358 @@
+package log_config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/everfir/logger-go/structs/sampling_config"
+	"github.com/everfir/logger-go/structs/tracer_config"
+)
+
+// 配置文件、LOGGER_ 环境变量及 Diff 共用字段上的 config 标签：
+//
+//	`config:"name[,hours][,nonnegative][,secret]"`
+//
+// hours 表示整数小时，同时接受 1h、24h 等时长字符串；nonnegative 表示整数不能为负；
+// secret 表示字符串或 map 的值在 Diff 中隐藏。没有 config 标签的字段（hook、producer 等）只能通过 Option 设置，
+// 匿名内嵌的结构体字段展开到外层。
+type tagOptions struct {
+	name        string
+	hours       bool
+	nonnegative bool
+	secret      bool
+}
+
+type field struct {
+	index []int
+	tagOptions
+}
+
+// sectionDefaults 配置段为 nil 时的初始值，未列出的类型使用零值
+var sectionDefaults = map[reflect.Type]func() interface{}{
+	reflect.TypeOf(tracer_config.TracerConfig{}): func() interface{} {
+		return tracer_config.DefaultTracerConfig.Clone()
+	},
+}
+
+// fields 返回结构体中带 config 标签的字段
+func fields(t reflect.Type) []field {
+	var ret []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("config")
+		if !ok {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				for _, inner := range fields(f.Type) {
+					inner.index = append([]int{i}, inner.index...)
+					ret = append(ret, inner)
+				}
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		opts := tagOptions{name: name}
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "hours":
+				opts.hours = true
+			case "nonnegative":
+				opts.nonnegative = true
+			case "secret":
+				opts.secret = true
+			}
+		}
+		ret = append(ret, field{index: []int{i}, tagOptions: opts})
+	}
+	return ret
+}
+
+// isSection 指向结构体的指针字段是一个配置段，如 tracer、outputs 中的 http
+func isSection(t reflect.Type) bool {
+	if t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := parsers[t.Elem()]
+	return !ok
+}
+
+// newSection 创建配置段的初始值
+func newSection(t reflect.Type) reflect.Value {
+	if def, ok := sectionDefaults[t.Elem()]; ok {
+		return reflect.ValueOf(def())
+	}
+	return reflect.New(t.Elem())
+}
+
+// bindStruct 将配置文件中的一个表覆盖到结构体上，返回所有不合法的key
+func bindStruct(v reflect.Value, values map[string]interface{}, prefix string) error {
+	byName := make(map[string]field)
+	for _, f := range fields(v.Type()) {
+		byName[f.name] = f
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(values) {
+		f, ok := byName[key]
+		if !ok {
+			errs = append(errs, &KeyError{Key: prefix + key, Err: errors.New("unknown key")})
+			continue
+		}
+		errs = append(errs, bind(v.FieldByIndex(f.index), values[key], f.tagOptions, prefix+key))
+	}
+	return errors.Join(errs...)
+}
+
+// bind 将解析后的值写入 v，返回的错误都是带完整key的 *KeyError
+func bind(v reflect.Value, value interface{}, opts tagOptions, key string) error {
+	keyError := func(err error) error {
+		if err == nil {
+			return nil
+		}
+		return &KeyError{Key: key, Err: err}
+	}
+
+	t := v.Type()
+	if parse, ok := parsers[t]; ok {
+		if s, ok := value.(string); ok {
+			parsed, err := parse(s)
+			if err != nil {
+				return keyError(err)
+			}
+			v.Set(reflect.ValueOf(parsed))
+			return nil
+		}
+		if t.Kind() != reflect.Struct {
+			return keyError(fmt.Errorf("expect string, got %T", value))
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		s, err := asString(value)
+		v.SetString(s)
+		return keyError(err)
+	case reflect.Bool:
+		b, err := asBool(value)
+		v.SetBool(b)
+		return keyError(err)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int
+		var err error
+		switch {
+		case opts.hours:
+			n, err = asHours(value)
+		case opts.nonnegative:
+			n, err = asNonNegativeInt(value)
+		default:
+			n, err = asInt(value)
+		}
+		v.SetInt(int64(n))
+		return keyError(err)
+	case reflect.Float32, reflect.Float64:
+		f, err := asFloat(value)
+		v.SetFloat(f)
+		return keyError(err)
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(newSection(t))
+		}
+		return bind(v.Elem(), value, opts, key)
+	case reflect.Struct:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return keyError(fmt.Errorf("expect table, got %T", value))
+		}
+		return bindStruct(v, values, key+".")
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			ss, err := asStringSlice(value)
+			if err == nil {
+				v.Set(reflect.ValueOf(ss).Convert(t))
+			}
+			return keyError(err)
+		}
+		return bindSlice(v, value, key)
+	case reflect.Map:
+		return bindMap(v, value, key)
+	default:
+		return keyError(fmt.Errorf("unsupported type %s", t))
+	}
+}
+
+// bindSlice 支持表的列表或同样结构的JSON字符串（用于环境变量），任一项不合法时不修改原值
+func bindSlice(v reflect.Value, value interface{}, key string) error {
+	if s, ok := value.(string); ok {
+		var items []interface{}
+		decoder := json.NewDecoder(strings.NewReader(s))
+		decoder.UseNumber()
+		if err := decoder.Decode(&items); err != nil {
+			return &KeyError{Key: key, Err: fmt.Errorf("expect JSON list: %w", err)}
+		}
+		value = items
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return &KeyError{Key: key, Err: fmt.Errorf("expect list, got %T", value)}
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	var errs []error
+	for i, item := range items {
+		itemKey := fmt.Sprintf("%s.%d", key, i)
+		elem := slice.Index(i)
+		if err := bind(elem, item, tagOptions{}, itemKey); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if validator, ok := elem.Addr().Interface().(interface{ Validate() error }); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, &KeyError{Key: itemKey, Err: err})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	v.Set(slice)
+	return nil
+}
+
+// bindMap 支持表或 k1=v1,k2=v2 格式的字符串，key 和值按各自的类型解析，任一项不合法时不修改原值
+func bindMap(v reflect.Value, value interface{}, key string) error {
+	if s, ok := value.(string); ok {
+		pairs, err := asStringMap(s)
+		if err != nil {
+			return &KeyError{Key: key, Err: err}
+		}
+		table := make(map[string]interface{}, len(pairs))
+		for k, v := range pairs {
+			table[k] = v
+		}
+		value = table
+	}
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return &KeyError{Key: key, Err: fmt.Errorf("expect table, got %T", value)}
+	}
+
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(table))
+	var errs []error
+	for _, name := range sortedKeys(table) {
+		k := reflect.New(t.Key()).Elem()
+		if err := bind(k, name, tagOptions{}, key+"."+name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		e := reflect.New(t.Elem()).Elem()
+		if err := bind(e, table[name], tagOptions{}, key+"."+name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.SetMapIndex(k, e)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	v.Set(m)
+	return nil
+}
+
+// bindEnv 使用 prefix+NAME 形式的环境变量覆盖结构体，配置段使用 prefix+SECTION_NAME，
+// 且仅在存在对应环境变量时创建；返回是否读取到了环境变量
+func bindEnv(v reflect.Value, prefix string) (bool, error) {
+	var found bool
+	var errs []error
+	for _, f := range fields(v.Type()) {
+		name := prefix + strings.ToUpper(f.name)
+		fv := v.FieldByIndex(f.index)
+
+		if isSection(fv.Type()) {
+			section := fv
+			if fv.IsNil() {
+				section = newSection(fv.Type())
+			}
+			ok, err := bindEnv(section.Elem(), name+"_")
+			if ok && fv.IsNil() {
+				fv.Set(section)
+			}
+			found = found || ok
+			errs = append(errs, err)
+			continue
+		}
+
+		if value, ok := os.LookupEnv(name); ok {
+			found = true
+			errs = append(errs, bind(fv, value, f.tagOptions, name))
+		}
+	}
+	return found, errors.Join(errs...)
+}
+
+// flattenStruct 将结构体展开为 prefix+key -> 字符串值，nil 配置段不展开
+func flattenStruct(v reflect.Value, prefix string, values map[string]string) {
+	for _, f := range fields(v.Type()) {
+		fv := v.FieldByIndex(f.index)
+		if isSection(fv.Type()) {
+			if !fv.IsNil() {
+				flattenStruct(fv.Elem(), prefix+f.name+".", values)
+			}
+			continue
+		}
+		values[prefix+f.name] = format(fv, f.tagOptions)
+	}
+}
+
+// format 将单个值转为字符串：列表使用逗号分隔，map 为排序后的 k=v，结构体为 {k=v,...}
+func format(v reflect.Value, opts tagOptions) string {
+	if ls, ok := v.Interface().(sampling_config.LevelSampling); ok {
+		return fmt.Sprintf("%d/%d", ls.First, ls.Thereafter)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return format(v.Elem(), opts)
+	case reflect.String:
+		if opts.secret && v.Len() > 0 {
+			return "***"
+		}
+		return v.String()
+	case reflect.Struct:
+		values := make(map[string]string)
+		flattenStruct(v, "", values)
+		items := make([]string, 0, len(values))
+		for _, key := range sortedKeys(values) {
+			items = append(items, key+"="+values[key])
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	case reflect.Slice:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, format(v.Index(i), opts))
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := "***"
+			if !opts.secret {
+				value = format(iter.Value(), opts)
+			}
+			items = append(items, format(iter.Key(), opts)+"="+value)
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
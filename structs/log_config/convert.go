@@ -0,0 +1,270 @@
+package log_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/everfir/logger-go/structs/async_config"
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/everfir/logger-go/structs/redact_config"
+	"github.com/everfir/logger-go/structs/sampling_config"
+	"github.com/everfir/logger-go/structs/tracer_config"
+)
+
+// parsers 其他包中定义、需要从字符串解析的类型，枚举类型在这里校验取值
+var parsers = map[reflect.Type]func(string) (interface{}, error){
+	reflect.TypeOf(log_level.Level(0)): func(s string) (interface{}, error) {
+		return asLevel(s)
+	},
+	reflect.TypeOf(time.Duration(0)): func(s string) (interface{}, error) {
+		return asDuration(s)
+	},
+	reflect.TypeOf(tracer_config.Compression(0)): func(s string) (interface{}, error) {
+		switch s {
+		case "gzip":
+			return tracer_config.Gzip, nil
+		case "none", "":
+			return tracer_config.No, nil
+		default:
+			return nil, fmt.Errorf("unsupported compression %q", s)
+		}
+	},
+	reflect.TypeOf(tracer_config.Protocol(0)): func(s string) (interface{}, error) {
+		switch s {
+		case "http/protobuf", "":
+			return tracer_config.HTTPProtobuf, nil
+		case "grpc":
+			return tracer_config.GRPC, nil
+		default:
+			return nil, fmt.Errorf("unsupported protocol %q", s)
+		}
+	},
+	reflect.TypeOf(tracer_config.Sampler("")): func(s string) (interface{}, error) {
+		if sampler := tracer_config.Sampler(s); sampler.Valid() {
+			return sampler, nil
+		}
+		return nil, fmt.Errorf("unsupported sampler %q", s)
+	},
+	reflect.TypeOf(redact_config.Strategy("")): func(s string) (interface{}, error) {
+		switch strategy := redact_config.Strategy(s); strategy {
+		case "", redact_config.Mask, redact_config.Hash, redact_config.Truncate:
+			return strategy, nil
+		default:
+			return nil, fmt.Errorf("unsupported strategy %q", s)
+		}
+	},
+	reflect.TypeOf(async_config.Policy("")): func(s string) (interface{}, error) {
+		switch policy := async_config.Policy(s); policy {
+		case "", async_config.Block, async_config.DropNewest, async_config.DropOldest, async_config.DropBelowLevel:
+			return policy, nil
+		default:
+			return nil, fmt.Errorf("unsupported policy %q", s)
+		}
+	},
+	reflect.TypeOf(output_config.Encoding("")): func(s string) (interface{}, error) {
+		switch encoding := output_config.Encoding(s); encoding {
+		case "", output_config.JSON, output_config.Console:
+			return encoding, nil
+		default:
+			return nil, fmt.Errorf("unsupported encoding %q", s)
+		}
+	},
+	// 按级别的采样参数也可以写成 first/thereafter，如 debug=10/100
+	reflect.TypeOf(sampling_config.LevelSampling{}): func(s string) (interface{}, error) {
+		first, thereafter, ok := strings.Cut(s, "/")
+		if !ok {
+			return nil, fmt.Errorf("expect first/thereafter, got %q", s)
+		}
+		var ls sampling_config.LevelSampling
+		var err error
+		if ls.First, err = asNonNegativeInt(first); err != nil {
+			return nil, fmt.Errorf("first: %w", err)
+		}
+		if ls.Thereafter, err = asNonNegativeInt(thereafter); err != nil {
+			return nil, fmt.Errorf("thereafter: %w", err)
+		}
+		return ls, nil
+	},
+}
+
+func asString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("expect string, got %T", v)
+	}
+}
+
+func asBool(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return false, fmt.Errorf("expect bool, got %T", v)
+	}
+}
+
+func asInt(v interface{}) (int, error) {
+	switch v := v.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("expect integer, got %v", v)
+		}
+		return int(v), nil
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err
+	case string:
+		return strconv.Atoi(v)
+	default:
+		return 0, fmt.Errorf("expect integer, got %T", v)
+	}
+}
+
+func asNonNegativeInt(v interface{}) (int, error) {
+	n, err := asInt(v)
+	if err == nil && n < 0 {
+		err = fmt.Errorf("must not be negative, got %d", n)
+	}
+	return n, err
+}
+
+func asFloat(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("expect number, got %T", v)
+	}
+}
+
+// asLevel 日志级别使用字符串表示，如 debug、info
+func asLevel(v interface{}) (log_level.Level, error) {
+	s, err := asString(v)
+	if err != nil {
+		return 0, err
+	}
+	return log_level.NewLogLevel(strings.ToLower(s))
+}
+
+// asHours 轮转时间支持整数小时或时长字符串，如 1h、24h
+func asHours(v interface{}) (int, error) {
+	s, ok := v.(string)
+	if !ok {
+		return asNonNegativeInt(v)
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return asNonNegativeInt(n)
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 || d%time.Hour != 0 {
+		return 0, fmt.Errorf("must be a positive multiple of 1h, got %s", s)
+	}
+	return int(d / time.Hour), nil
+}
+
+// asDuration 时长使用字符串表示，如 10s、1m
+func asDuration(v interface{}) (time.Duration, error) {
+	s, err := asString(v)
+	if err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("must not be negative, got %s", s)
+	}
+	return d, err
+}
+
+// asStringSlice 支持列表或逗号分隔的字符串
+func asStringSlice(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		ret := make([]string, 0, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("item %d: expect string, got %T", i, item)
+			}
+			ret = append(ret, s)
+		}
+		return ret, nil
+	case []string:
+		return v, nil
+	case string:
+		var ret []string
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ret = append(ret, s)
+			}
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("expect list of strings, got %T", v)
+	}
+}
+
+// asStringMap 支持表或 k1=v1,k2=v2 格式的字符串
+func asStringMap(v interface{}) (map[string]string, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]string, len(v))
+		for key, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: expect string, got %T", key, item)
+			}
+			ret[key] = s
+		}
+		return ret, nil
+	case string:
+		ret := make(map[string]string)
+		for _, pair := range strings.Split(v, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid pair %q", pair)
+			}
+			ret[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("expect table of strings, got %T", v)
+	}
+}
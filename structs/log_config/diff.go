@@ -0,0 +1,39 @@
+package log_config
+
+import "reflect"
+
+// Change 描述一项配置变更
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Diff 返回两份配置之间的差异，key 与配置文件中的key一致
+func Diff(old, new *LogConfig) []Change {
+	oldValues, newValues := old.flatten(), new.flatten()
+
+	// 取两份配置key的并集，被删除的配置段（如脱敏、采样配置置空）同样需要报告
+	keys := make(map[string]struct{}, len(newValues))
+	for key := range oldValues {
+		keys[key] = struct{}{}
+	}
+	for key := range newValues {
+		keys[key] = struct{}{}
+	}
+
+	var changes []Change
+	for _, key := range sortedKeys(keys) {
+		if oldValues[key] != newValues[key] {
+			changes = append(changes, Change{Key: key, Old: oldValues[key], New: newValues[key]})
+		}
+	}
+	return changes
+}
+
+// flatten 将配置展开为 key -> 字符串值，secret 字段（如 header）的值会被隐藏
+func (config *LogConfig) flatten() map[string]string {
+	values := make(map[string]string)
+	flattenStruct(reflect.ValueOf(config).Elem(), "", values)
+	return values
+}
@@ -0,0 +1,60 @@
+package log_config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/everfir/logger-go/structs/redact_config"
+	"github.com/everfir/logger-go/structs/sampling_config"
+)
+
+func TestDiffReportsRemovedSections(t *testing.T) {
+	old := DefaultConfig.Clone()
+	old.RedactConfig = &redact_config.RedactConfig{Keys: []string{"password"}}
+	old.SamplingConfig = &sampling_config.SamplingConfig{LevelSampling: sampling_config.LevelSampling{First: 100, Thereafter: 10}}
+	old.Outputs = []output_config.OutputConfig{{Target: "app.log"}}
+
+	new := DefaultConfig.Clone()
+
+	changed := make(map[string]Change)
+	for _, change := range Diff(old, new) {
+		changed[change.Key] = change
+	}
+
+	for _, key := range []string{"redact.keys", "sampling.first", "outputs"} {
+		change, ok := changed[key]
+		if !ok {
+			t.Errorf("removed %s not reported", key)
+			continue
+		}
+		if change.Old == "" || change.New != "" {
+			t.Errorf("%s: got %q -> %q", key, change.Old, change.New)
+		}
+	}
+	if len(Diff(new, new.Clone())) != 0 {
+		t.Errorf("identical configs reported changes: %v", Diff(new, new.Clone()))
+	}
+}
+
+func TestDiffHidesSecrets(t *testing.T) {
+	old := DefaultConfig.Clone()
+	old.TracerConfig.Headers = map[string]string{"authorization": "old-token"}
+	old.Outputs = []output_config.OutputConfig{{
+		Target: "https://logs.example.com",
+		HTTP:   &output_config.HTTPConfig{Headers: map[string]string{"authorization": "old-token"}},
+	}}
+
+	new := old.Clone()
+	new.TracerConfig.Headers = map[string]string{"authorization": "new-token"}
+	new.Outputs[0].HTTP = &output_config.HTTPConfig{Headers: map[string]string{"authorization": "new-token"}, BatchSize: 10}
+
+	for _, change := range Diff(old, new) {
+		if strings.Contains(change.Old+change.New, "token") {
+			t.Errorf("%s leaks secret: %q -> %q", change.Key, change.Old, change.New)
+		}
+		if change.Key != "outputs" {
+			t.Errorf("unexpected change %s", change.Key)
+		}
+	}
+}
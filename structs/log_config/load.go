@@ -0,0 +1,119 @@
+package log_config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Format 配置文件格式
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+	TOML Format = "toml"
+)
+
+// EnvPrefix 配置覆盖环境变量前缀，如 LOGGER_LEVEL=debug、LOGGER_TRACER_ENABLE=false、LOGGER_REDACT_KEYS=password
+const EnvPrefix = "LOGGER_"
+
+// KeyError 描述配置中某个key的错误
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string { return e.Key + ": " + e.Err.Error() }
+func (e *KeyError) Unwrap() error { return e.Err }
+
+// FormatFromPath 根据文件扩展名推断配置格式
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	case ".toml":
+		return TOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension: %q", filepath.Ext(path))
+	}
+}
+
+// Load 以默认配置为基础，读取配置内容并返回新的配置
+func Load(r io.Reader, format Format) (*LogConfig, error) {
+	config := DefaultConfig.Clone()
+	if err := config.Decode(r, format); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadFile 以默认配置为基础，读取配置文件并返回新的配置
+func LoadFile(path string) (*LogConfig, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f, format)
+}
+
+// Decode 读取配置内容并覆盖到当前配置上，返回所有不合法的key
+func (config *LogConfig) Decode(r io.Reader, format Format) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read config failed: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	switch format {
+	case YAML:
+		err = yaml.Unmarshal(data, &values)
+	case JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&values)
+	case TOML:
+		err = toml.Unmarshal(data, &values)
+	default:
+		err = fmt.Errorf("unsupported config format: %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("decode %s config failed: %w", format, err)
+	}
+
+	return bindStruct(reflect.ValueOf(config).Elem(), values, "")
+}
+
+// ApplyLoggerEnv 使用 LOGGER_ 前缀的环境变量覆盖配置，变量名为配置文件中的key转大写，层级之间使用下划线，
+// 如 LOGGER_TRACER_ENABLE；列表使用逗号分隔，map 使用 k1=v1,k2=v2 格式，outputs 使用JSON
+func (config *LogConfig) ApplyLoggerEnv() error {
+	_, err := bindEnv(reflect.ValueOf(config).Elem(), EnvPrefix)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,202 @@
+package log_config
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/sampling_config"
+)
+
+func TestLoadNegativeHTTPRetries(t *testing.T) {
+	config, err := Load(strings.NewReader(`
+outputs:
+  - target: http://127.0.0.1:3100/loki/api/v1/push
+    http:
+      retries: -1
+`), YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := config.Outputs[0].HTTP.Retries; got != -1 {
+		t.Errorf("retries: got %d, want -1", got)
+	}
+}
+
+func TestLoadKafkaBrokers(t *testing.T) {
+	config, err := Load(strings.NewReader(`
+outputs:
+  - target: kafka://audit-log
+    kafka:
+      brokers: [127.0.0.1:9092, 127.0.0.1:9093]
+      compression: zstd
+      retries: -1
+`), YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kafka := config.Outputs[0].Kafka
+	if len(kafka.Brokers) != 2 || kafka.Compression != "zstd" || kafka.Retries != -1 {
+		t.Errorf("kafka config: %+v", kafka)
+	}
+
+	clone := kafka.Clone()
+	clone.Brokers[0] = "changed"
+	if kafka.Brokers[0] != "127.0.0.1:9092" {
+		t.Error("clone shares brokers")
+	}
+}
+
+func TestLoadLevelsAndDurations(t *testing.T) {
+	config, err := Load(strings.NewReader(`{
+		"level": "DEBUG",
+		"rotation_time": "48h",
+		"dedup_window": "1500ms",
+		"sampling": {"interval": "2s", "first": 10, "levels": {"info": {"first": 5, "thereafter": 50}, "warn": "1/2"}},
+		"async": {"flush_interval": "50ms", "drop_level": "warn"},
+		"outputs": [{"target": "debug.log", "max_level": "debug"}, {"target": "app.log"}]
+	}`), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Level != log_level.DebugLevel {
+		t.Errorf("level: got %s", config.Level)
+	}
+	if config.RotationTime != 48 {
+		t.Errorf("rotation_time: got %d", config.RotationTime)
+	}
+	if config.DedupWindow != 1500*time.Millisecond {
+		t.Errorf("dedup_window: got %s", config.DedupWindow)
+	}
+	sc := config.SamplingConfig
+	if sc.Interval != 2*time.Second || sc.First != 10 {
+		t.Errorf("sampling: got %+v", sc)
+	}
+	want := map[log_level.Level]sampling_config.LevelSampling{
+		log_level.InfoLevel: {First: 5, Thereafter: 50},
+		log_level.WarnLevel: {First: 1, Thereafter: 2},
+	}
+	if !reflect.DeepEqual(sc.Levels, want) {
+		t.Errorf("sampling.levels: got %v", sc.Levels)
+	}
+	if ac := config.AsyncConfig; ac.FlushInterval != 50*time.Millisecond || *ac.DropLevel != log_level.WarnLevel {
+		t.Errorf("async: got %+v", ac)
+	}
+	// max_level: debug 与未配置不同
+	if outputs := config.Outputs; *outputs[0].MaxLevel != log_level.DebugLevel || outputs[1].MaxLevel != nil {
+		t.Errorf("outputs: got %+v", outputs)
+	}
+}
+
+func TestLoadReportsEveryInvalidKey(t *testing.T) {
+	_, err := Load(strings.NewReader(`
+levle: debug
+level: verbose
+dedup_window: -1s
+rotation_time: 90m
+tracer:
+  protocol: udp
+  unknown: 1
+sampling:
+  levels:
+    info: 1-2
+outputs:
+  - target: app.log
+    encoding: xml
+  - target: ""
+`), YAML)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	want := []string{
+		"dedup_window", "level", "levle",
+		"outputs.0.encoding", "outputs.1",
+		"rotation_time", "sampling.levels.info",
+		"tracer.protocol", "tracer.unknown",
+	}
+	got := flattenKeys(err)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("keys: got %v, want %v\n%v", got, want, err)
+	}
+	if !strings.Contains(err.Error(), "levle: unknown key") {
+		t.Errorf("message: %v", err)
+	}
+}
+
+func TestApplyLoggerEnv(t *testing.T) {
+	config, err := Load(strings.NewReader(`
+level: debug
+output_files: [stdout]
+tracer:
+  enable: true
+  collector_endpoint: 127.0.0.1:4318
+`), YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("LOGGER_LEVEL", "warn")
+	t.Setenv("LOGGER_OUTPUT_FILES", "app.log, stderr")
+	t.Setenv("LOGGER_TRACER_ENABLE", "false")
+	t.Setenv("LOGGER_TRACER_HEADERS", "authorization=token")
+	t.Setenv("LOGGER_SAMPLING_LEVELS", "debug=10/100")
+	t.Setenv("LOGGER_OUTPUTS", `[{"target": "audit.log", "level": "error"}]`)
+	if err = config.ApplyLoggerEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 环境变量覆盖配置文件，未设置的key保持配置文件中的值
+	if config.Level != log_level.WarnLevel {
+		t.Errorf("level: got %s", config.Level)
+	}
+	if !reflect.DeepEqual(config.OutputFiles, []string{"app.log", "stderr"}) {
+		t.Errorf("output_files: got %v", config.OutputFiles)
+	}
+	if tc := config.TracerConfig; tc.Enable || tc.CollectorEndpoint != "127.0.0.1:4318" || tc.Headers["authorization"] != "token" {
+		t.Errorf("tracer: got %+v", tc)
+	}
+	if ls := config.SamplingConfig.Levels[log_level.DebugLevel]; ls.First != 10 || ls.Thereafter != 100 {
+		t.Errorf("sampling.levels: got %v", config.SamplingConfig.Levels)
+	}
+	if len(config.Outputs) != 1 || config.Outputs[0].Level != log_level.ErrorLevel {
+		t.Errorf("outputs: got %+v", config.Outputs)
+	}
+	// 没有对应环境变量的配置段不会被创建
+	if config.RedactConfig != nil || config.AsyncConfig != nil {
+		t.Errorf("unexpected sections: %+v %+v", config.RedactConfig, config.AsyncConfig)
+	}
+
+	t.Setenv("LOGGER_ASYNC_POLICY", "drop_all")
+	t.Setenv("LOGGER_MAX_BACKUPS", "-1")
+	err = config.ApplyLoggerEnv()
+	if got := flattenKeys(err); !reflect.DeepEqual(got, []string{"LOGGER_ASYNC_POLICY", "LOGGER_MAX_BACKUPS"}) {
+		t.Errorf("env errors: got %v", err)
+	}
+}
+
+// flattenKeys 返回 errors.Join 组合的所有 KeyError 的key，按字母排序
+func flattenKeys(err error) []string {
+	var keys []string
+	var walk func(error)
+	walk = func(err error) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				walk(err)
+			}
+			return
+		}
+		var ke *KeyError
+		if errors.As(err, &ke) {
+			keys = append(keys, ke.Key)
+		}
+	}
+	walk(err)
+	sort.Strings(keys)
+	return keys
+}
@@ -2,48 +2,152 @@ package log_config
 
 import (
 	"os"
+	"time"
 
+	"github.com/everfir/logger-go/structs/async_config"
+	"github.com/everfir/logger-go/structs/hook"
 	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/output_config"
+	"github.com/everfir/logger-go/structs/redact_config"
+	"github.com/everfir/logger-go/structs/sampling_config"
 	"github.com/everfir/logger-go/structs/tracer_config"
 )
 
 // LogConfig 定义日志配置结构
 type LogConfig struct {
 	// 基础信息
-	ServiceName string // 服务名称
-	PodIP       string // 容器IP
+	ServiceName string `config:"service_name"` // 服务名称
+	PodIP       string `config:"pod_ip"`       // 容器IP
 
-	Level      log_level.Level // 日志级别：定义记录哪个级别及以上的日志
-	StackTrace log_level.Level // 堆栈跟踪级别：定义在哪个级别及以上的日志中包含堆栈跟踪
+	Level      log_level.Level `config:"level"`       // 日志级别：定义记录哪个级别及以上的日志
+	StackTrace log_level.Level `config:"stack_trace"` // 堆栈跟踪级别：定义在哪个级别及以上的日志中包含堆栈跟踪
 
-	Compress     bool // 旧日志文件压缩：是否压缩旧的日志文件
-	MaxBackups   int  // 旧日志文件最大保留个数：超过此数量的旧文件将被删除
-	RotationTime int  // 日志轮转时间间隔（分钟）：多久创建一个新的日志文件
+	Compress     bool `config:"compress"`                // 旧日志文件压缩：是否压缩旧的日志文件
+	MaxBackups   int  `config:"max_backups,nonnegative"` // 旧日志文件最大保留个数：超过此数量的旧文件将被删除
+	RotationTime int  `config:"rotation_time,hours"`     // 日志轮转时间间隔（小时）：多久创建一个新的日志文件
 
 	// 目录为当前工作目录
-	OutputFiles []string // 日志输出文件名：日志文件的保存位置，可以是文件路径或 "stdout"/"stderr"
+	OutputFiles []string `config:"output_files"` // 日志输出文件名：日志文件的保存位置，可以是文件路径或 "stdout"/"stderr"
 
-	ErrorFiles []string // 错误日志文件名：错误级别日志的额外输出位置
+	ErrorFiles []string `config:"error_files"` // 错误日志文件名：错误级别日志的额外输出位置
+
+	// 可以单独配置级别、编码、采样及轮转的输出，与 OutputFiles、ErrorFiles 同时生效
+	Outputs []output_config.OutputConfig `config:"outputs"`
 
 	// 链路追踪
-	TracerConfig *tracer_config.TracerConfig
+	TracerConfig *tracer_config.TracerConfig `config:"tracer"`
+
+	// 敏感信息脱敏，nil 表示不脱敏
+	RedactConfig *redact_config.RedactConfig `config:"redact"`
+
+	// 相同级别和消息的日志采样，nil 表示不采样
+	SamplingConfig *sampling_config.SamplingConfig `config:"sampling"`
+
+	// 异步写入，nil 表示在调用方goroutine中同步写入
+	AsyncConfig *async_config.AsyncConfig `config:"async"`
+
+	// 连续重复日志的合并窗口：级别、消息及字段都相同且间隔不超过窗口的日志只输出一次，0 表示不合并
+	DedupWindow time.Duration `config:"dedup_window"`
+
+	// 判断是否重复时额外忽略的字段，如请求级别的上下文字段；trace_id、span_id、trace_flags 总是被忽略
+	DedupIgnoreFields []string `config:"dedup_ignore_fields"`
+
+	// kafka:// 输出默认使用的 producer
+	KafkaProducer output_config.KafkaProducer
+
+	// 日志写入前后的钩子，按注册顺序调用
+	Hooks     []hook.Hook
+	PostHooks []hook.PostHook
+
+	// Recover 捕获panic后是否在记录日志后重新panic，默认记录日志后吞掉panic
+	RePanic bool `config:"re_panic"`
+
+	// 收到 SIGTERM/SIGINT 时刷新日志及span后再退出
+	FlushOnSignal bool `config:"flush_on_signal"`
+
+	// 程序自身也监听了 SIGTERM/SIGINT：FlushOnSignal 只刷新，不重新发送信号，由程序自身退出
+	SignalHandled bool `config:"signal_handled"`
+
+	// 配置文件热加载检查间隔：仅对 InitFromFile 生效，0 表示不开启
+	WatchInterval time.Duration `config:"watch_interval"`
 }
 
 // 默认配置
 var DefaultConfig = LogConfig{
-	PodIP:       os.Getenv("PodIP"),
-	ServiceName: os.Getenv("ServiceName"),
+	PodIP:       getenv("POD_IP", "PodIP"),
+	ServiceName: getenv("SERVICE_NAME", "ServiceName"),
 
 	Level:        log_level.InfoLevel,
 	StackTrace:   log_level.FatalLevel,
 	OutputFiles:  []string{"stdout"},
-	ErrorFiles:   []string{"stderr"},
 	TracerConfig: &tracer_config.DefaultTracerConfig,
 }
 
+// Clone 复制配置，避免修改共享的默认配置
+func (config *LogConfig) Clone() *LogConfig {
+	ret := *config
+	ret.OutputFiles = append([]string(nil), config.OutputFiles...)
+	ret.ErrorFiles = append([]string(nil), config.ErrorFiles...)
+	ret.DedupIgnoreFields = append([]string(nil), config.DedupIgnoreFields...)
+	ret.Outputs = make([]output_config.OutputConfig, 0, len(config.Outputs))
+	for i := range config.Outputs {
+		ret.Outputs = append(ret.Outputs, config.Outputs[i].Clone())
+	}
+	ret.Hooks = append([]hook.Hook(nil), config.Hooks...)
+	ret.PostHooks = append([]hook.PostHook(nil), config.PostHooks...)
+	ret.TracerConfig = config.TracerConfig.Clone()
+	ret.RedactConfig = config.RedactConfig.Clone()
+	ret.SamplingConfig = config.SamplingConfig.Clone()
+	ret.AsyncConfig = config.AsyncConfig.Clone()
+	return &ret
+}
+
+// AllOutputs 返回所有输出：Outputs 以及由 OutputFiles、ErrorFiles 转换的输出
+func (config *LogConfig) AllOutputs() []output_config.OutputConfig {
+	outputs := make([]output_config.OutputConfig, 0, len(config.Outputs)+len(config.OutputFiles)+len(config.ErrorFiles))
+	outputs = append(outputs, config.Outputs...)
+	for _, target := range config.OutputFiles {
+		outputs = append(outputs, output_config.OutputConfig{Target: target})
+	}
+	for _, target := range config.ErrorFiles {
+		outputs = append(outputs, output_config.OutputConfig{Target: target, Level: log_level.ErrorLevel})
+	}
+	return outputs
+}
+
+// ResourceServiceName 日志及span资源中使用的服务名：tracer 中单独指定的服务名优先，否则使用 ServiceName
+func (config *LogConfig) ResourceServiceName() string {
+	if config.TracerConfig != nil && config.TracerConfig.ServiceName != "" {
+		return config.TracerConfig.ServiceName
+	}
+	return config.ServiceName
+}
+
+// ApplyEnv 使用 OTEL_* 标准环境变量覆盖配置，返回被忽略的取值对应的警告
+// OTEL_SERVICE_NAME 覆盖 ServiceName，日志及span资源中的服务名都由 ResourceServiceName 决定
+func (config *LogConfig) ApplyEnv() (warnings []error) {
+	if v := os.Getenv(tracer_config.EnvServiceName); v != "" {
+		config.ServiceName = v
+	}
+	return config.TracerConfig.ApplyEnv()
+}
+
 func (config *LogConfig) FixDefault() {
 	if config.RotationTime == 0 {
 		config.RotationTime = 1
 	}
+	if config.TracerConfig != nil {
+		config.TracerConfig.ServiceName = config.ResourceServiceName()
+	}
 	config.TracerConfig.FixDefault()
 }
+
+// getenv 返回第一个非空的环境变量值，兼容新旧两种环境变量命名
+func getenv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
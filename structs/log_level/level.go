@@ -36,6 +36,24 @@ func (level Level) ToZapLevel() zapcore.Level {
 	}
 }
 
+// String 返回日志级别的字符串表示，与 NewLogLevel 的参数一致
+func (level Level) String() string {
+	switch level {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return fmt.Sprintf("Level(%d)", int(level))
+	}
+}
+
 func NewLogLevel(level string) (ret Level, err error) {
 	switch level {
 	case "debug":
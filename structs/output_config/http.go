@@ -0,0 +1,80 @@
+package output_config
+
+import (
+	"fmt"
+	"time"
+)
+
+// HTTP 输出的预设格式
+const (
+	PresetNDJSON        = ""              // 换行分隔的JSON
+	PresetLoki          = "loki"          // Loki push API
+	PresetElasticsearch = "elasticsearch" // Elasticsearch _bulk API
+)
+
+// HTTPConfig HTTP 批量输出配置，Target 为 http:// 或 https:// 时生效，nil 时使用默认值
+type HTTPConfig struct {
+	Preset  string            `config:"preset"`         // 请求格式：空（NDJSON）、loki、elasticsearch
+	Headers map[string]string `config:"headers,secret"` // 请求头，如鉴权信息
+
+	BatchSize     int           `config:"batch_size,nonnegative"`  // 每批最多的日志条数，默认 1000
+	BatchBytes    int           `config:"batch_bytes,nonnegative"` // 每批最多的字节数（压缩前），默认 1MiB
+	FlushInterval time.Duration `config:"flush_interval"`          // 最长发送间隔，默认 1s
+	Timeout       time.Duration `config:"timeout"`                 // 单次请求超时，默认 10s
+	Retries       int           `config:"retries"`                 // 5xx/429 或网络错误时的重试次数，0 使用默认的 3 次，负数表示不重试
+	Compression   string        `config:"compression"`             // gzip（默认）或 none
+
+	// 发送失败的批次暂存到本地目录，重新可用后按顺序重发；QueueMaxBytes 为 0 时使用默认的 256MiB，为负数时不暂存
+	QueueDir      string `config:"queue_dir"`
+	QueueMaxBytes int64  `config:"queue_max_bytes"`
+
+	Index  string            `config:"index"`  // elasticsearch：写入的索引或数据流，默认 logs-服务名
+	Labels map[string]string `config:"labels"` // loki：stream 标签，默认包含 service_name，另按 level 区分 stream
+}
+
+// Clone 复制配置
+func (config *HTTPConfig) Clone() *HTTPConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	ret.Headers = cloneMap(config.Headers)
+	ret.Labels = cloneMap(config.Labels)
+	return &ret
+}
+
+// Validate 检查 HTTP 输出配置
+func (config *HTTPConfig) Validate() error {
+	if config == nil {
+		return nil
+	}
+
+	switch config.Preset {
+	case PresetNDJSON, PresetLoki, PresetElasticsearch:
+	default:
+		return fmt.Errorf("unsupported http preset %q", config.Preset)
+	}
+
+	switch config.Compression {
+	case "", "gzip", "none":
+	default:
+		return fmt.Errorf("unsupported http compression %q", config.Compression)
+	}
+
+	if config.BatchSize < 0 || config.BatchBytes < 0 || config.FlushInterval < 0 || config.Timeout < 0 {
+		return fmt.Errorf("http batch settings must not be negative")
+	}
+	return nil
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(m))
+	for key, value := range m {
+		ret[key] = value
+	}
+	return ret
+}
@@ -0,0 +1,71 @@
+package output_config
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// KafkaMessage 发送到 Kafka 的一条消息
+type KafkaMessage struct {
+	Topic string
+	Key   []byte // 按 KafkaConfig.KeyField 字段的值分区，字段不存在时为 nil
+	Value []byte // JSON 编码的日志
+	Time  time.Time
+}
+
+// KafkaProducer 批量发送消息，未设置 KafkaConfig.Brokers 时可以基于 sarama 等客户端自行实现
+// 返回错误表示整批发送失败；日志器不会关闭 producer，需由使用方在 logger.Close 之后关闭
+type KafkaProducer interface {
+	Produce(ctx context.Context, messages []KafkaMessage) error
+}
+
+// KafkaConfig Kafka 输出配置，Target 为 kafka://topic 时生效，nil 时使用默认值
+type KafkaConfig struct {
+	// 发送消息的 producer，nil 时设置了 Brokers 则使用内置的 producer，否则使用 LogConfig.KafkaProducer
+	Producer KafkaProducer
+
+	Brokers     []string `config:"brokers"`     // 内置 producer 连接的 broker 地址，如 127.0.0.1:9092
+	Compression string   `config:"compression"` // 内置 producer 的压缩方式：none（默认）、gzip、snappy、lz4、zstd
+
+	KeyField string `config:"key_field"` // 作为消息 key 的字段，默认 trace_id
+
+	BatchSize     int           `config:"batch_size,nonnegative"`  // 每批最多的消息条数，默认 500
+	BatchBytes    int           `config:"batch_bytes,nonnegative"` // 每批最多的字节数，默认 1MiB
+	FlushInterval time.Duration `config:"flush_interval"`          // 最长发送间隔，默认 1s
+	Timeout       time.Duration `config:"timeout"`                 // 单次发送超时，默认 10s
+	Retries       int           `config:"retries"`                 // 发送失败时的重试次数，0 使用默认的 3 次，负数表示不重试
+	BufferSize    int           `config:"buffer_size,nonnegative"` // 内存中等待发送的最多条数，默认 BatchSize 的 10 倍，超过后写入 Fallback
+
+	// 发送失败及缓冲区满时写入的 ./log 目录下的文件，默认 kafka-<topic>.log，轮转配置与 LogConfig 相同
+	Fallback string `config:"fallback"`
+}
+
+// Clone 复制配置
+func (config *KafkaConfig) Clone() *KafkaConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	ret.Brokers = append([]string(nil), config.Brokers...)
+	return &ret
+}
+
+// Validate 检查 Kafka 输出配置
+func (config *KafkaConfig) Validate() error {
+	if config == nil {
+		return nil
+	}
+
+	switch config.Compression {
+	case "", "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		return fmt.Errorf("unsupported kafka compression %q", config.Compression)
+	}
+
+	if config.BatchSize < 0 || config.BatchBytes < 0 || config.FlushInterval < 0 || config.Timeout < 0 || config.BufferSize < 0 {
+		return fmt.Errorf("kafka batch settings must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package output_config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/everfir/logger-go/structs/log_level"
+	"github.com/everfir/logger-go/structs/sampling_config"
+)
+
+// Encoding 日志编码格式
+type Encoding string
+
+const (
+	JSON    Encoding = "json"    // 每行一个JSON对象
+	Console Encoding = "console" // 以制表符分隔的可读格式
+)
+
+// OutputConfig 单个日志输出的配置
+type OutputConfig struct {
+	// Target 输出目标：stdout、stderr，
+	// syslog://、syslog+udp://host:port、syslog+tcp://host:port、syslog+unix:///path、unix:///dev/log，
+	// tcp://host:port、udp://host:port、unix:///path 网络输出，http://、https:// 批量发送，
+	// kafka://topic 发送到 Kafka（需导入 sinks/kafka 包），其余作为 ./log 目录下的文件名
+	Target string `config:"target"`
+
+	Encoding Encoding `config:"encoding"` // 编码格式，默认 json
+
+	// 输出的级别范围，实际的最低级别不低于 LogConfig.Level；MaxLevel 为 nil 时不限制最高级别，
+	// 设置为 debug 时只输出 debug 日志
+	Level    log_level.Level  `config:"level"`
+	MaxLevel *log_level.Level `config:"max_level"`
+
+	// 该输出单独的采样配置，与全局采样同时生效
+	Sampling *sampling_config.SamplingConfig `config:"sampling"`
+
+	// 文件轮转配置，nil 时使用 LogConfig 中的配置
+	Rotation *Rotation `config:"rotation"`
+
+	// HTTP 批量发送配置，仅对 http://、https:// 输出生效
+	HTTP *HTTPConfig `config:"http"`
+
+	// Kafka 发送配置，仅对 kafka:// 输出生效
+	Kafka *KafkaConfig `config:"kafka"`
+}
+
+// Rotation 文件轮转配置
+type Rotation struct {
+	Compress     bool `config:"compress"`                // 是否压缩旧的日志文件
+	MaxBackups   int  `config:"max_backups,nonnegative"` // 旧日志文件最大保留个数
+	RotationTime int  `config:"rotation_time,hours"`     // 轮转时间间隔（小时）
+}
+
+// IsStandard 是否输出到标准输出或标准错误
+func (config *OutputConfig) IsStandard() bool {
+	return config.Target == "stdout" || config.Target == "stderr"
+}
+
+// Clone 复制配置
+func (config *OutputConfig) Clone() OutputConfig {
+	ret := *config
+	ret.Sampling = config.Sampling.Clone()
+	ret.HTTP = config.HTTP.Clone()
+	ret.Kafka = config.Kafka.Clone()
+	if config.MaxLevel != nil {
+		level := *config.MaxLevel
+		ret.MaxLevel = &level
+	}
+	if config.Rotation != nil {
+		rotation := *config.Rotation
+		ret.Rotation = &rotation
+	}
+	return ret
+}
+
+// Validate 检查输出配置
+func (config *OutputConfig) Validate() error {
+	if config.Target == "" {
+		return errors.New("output target must not be empty")
+	}
+
+	switch config.Encoding {
+	case "", JSON, Console:
+	default:
+		return fmt.Errorf("output %s: unsupported encoding %q", config.Target, config.Encoding)
+	}
+
+	if config.MaxLevel != nil && *config.MaxLevel < config.Level {
+		return fmt.Errorf("output %s: max level %s is lower than level %s", config.Target, *config.MaxLevel, config.Level)
+	}
+
+	if config.Rotation != nil && (config.Rotation.MaxBackups < 0 || config.Rotation.RotationTime < 0) {
+		return fmt.Errorf("output %s: rotation settings must not be negative", config.Target)
+	}
+
+	if err := config.Sampling.Validate(); err != nil {
+		return fmt.Errorf("output %s: %w", config.Target, err)
+	}
+	if err := config.HTTP.Validate(); err != nil {
+		return fmt.Errorf("output %s: %w", config.Target, err)
+	}
+	if err := config.Kafka.Validate(); err != nil {
+		return fmt.Errorf("output %s: %w", config.Target, err)
+	}
+	return nil
+}
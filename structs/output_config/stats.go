@@ -0,0 +1,18 @@
+package output_config
+
+import "time"
+
+// Stats 输出的运行状态
+type Stats struct {
+	Target string
+
+	Connected  bool   // 网络输出是否已连接
+	Written    uint64 // 已写入的日志条数
+	Dropped    uint64 // 因缓冲区满或断开且无法暂存而丢弃的日志条数
+	Spilled    uint64 // 断开期间暂存到本地文件的日志条数
+	SpillBytes int64  // 当前待重发的暂存文件大小
+	Reconnects uint64 // 重新连接成功的次数
+
+	LastError     string    // 最近一次错误
+	LastErrorTime time.Time // 最近一次错误的时间
+}
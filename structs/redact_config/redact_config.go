@@ -0,0 +1,86 @@
+package redact_config
+
+import "fmt"
+
+// Strategy 脱敏方式
+type Strategy string
+
+const (
+	Mask     Strategy = "mask"     // 替换为固定的掩码
+	Hash     Strategy = "hash"     // 替换为以 HashKey 为密钥的 HMAC-SHA256 摘要，相同的值脱敏后相同，便于关联
+	Truncate Strategy = "truncate" // 保留前 Keep 个字符，其余替换为掩码
+)
+
+// 内置的敏感信息匹配规则
+const (
+	Email      = "email"
+	Phone      = "phone"
+	JWT        = "jwt"
+	CreditCard = "credit_card"
+)
+
+// RedactConfig 日志脱敏配置，对日志输出、span事件属性及记录到日志的baggage生效
+type RedactConfig struct {
+	// 按字段key脱敏，支持精确匹配和通配符，如 password、*token*，不区分大小写
+	Keys []string `config:"keys"`
+
+	// 按正则匹配字符串类型的字段值及日志消息，仅替换匹配的部分
+	Patterns []string `config:"patterns"`
+
+	// 启用的内置规则：email、phone、jwt、credit_card
+	Builtins []string `config:"builtins"`
+
+	Strategy Strategy `config:"strategy"`         // 脱敏方式，默认 mask
+	Keep     int      `config:"keep,nonnegative"` // truncate 时保留的字符数，默认 3
+
+	// hash 使用的密钥，使用 hash 时必须设置，避免通过枚举手机号等取值反推原文
+	HashKey string `config:"hash_key,secret"`
+}
+
+// Clone 复制配置
+func (config *RedactConfig) Clone() *RedactConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	ret.Keys = append([]string(nil), config.Keys...)
+	ret.Patterns = append([]string(nil), config.Patterns...)
+	ret.Builtins = append([]string(nil), config.Builtins...)
+	return &ret
+}
+
+// Enabled 是否配置了脱敏规则
+func (config *RedactConfig) Enabled() bool {
+	return config != nil && len(config.Keys)+len(config.Patterns)+len(config.Builtins) > 0
+}
+
+// Validate 检查脱敏方式、hash 密钥及内置规则是否合法，正则在创建脱敏器时检查
+func (config *RedactConfig) Validate() error {
+	if config == nil {
+		return nil
+	}
+
+	switch config.Strategy {
+	case "", Mask, Hash, Truncate:
+	default:
+		return fmt.Errorf("unsupported redact strategy %q", config.Strategy)
+	}
+
+	if config.Strategy == Hash && config.HashKey == "" {
+		return fmt.Errorf("redact strategy %q requires a hash key", Hash)
+	}
+
+	if config.Keep < 0 {
+		return fmt.Errorf("redact keep must not be negative, got %d", config.Keep)
+	}
+
+	for _, name := range config.Builtins {
+		switch name {
+		case Email, Phone, JWT, CreditCard:
+		default:
+			return fmt.Errorf("unsupported redact builtin %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package sampling_config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/everfir/logger-go/structs/log_level"
+)
+
+// LevelSampling 单个级别的采样参数
+// 每个统计周期内，相同级别和消息的日志先输出 First 条，之后每 Thereafter 条输出一条，Thereafter 为0时丢弃其余日志
+type LevelSampling struct {
+	First      int `config:"first,nonnegative"`
+	Thereafter int `config:"thereafter,nonnegative"`
+}
+
+// SamplingConfig 日志采样配置，按级别和消息统计
+type SamplingConfig struct {
+	Interval time.Duration `config:"interval"` // 统计周期，默认1s
+
+	LevelSampling                                   // 默认的采样参数，First 为0时不采样
+	Levels        map[log_level.Level]LevelSampling `config:"levels"` // 按级别覆盖默认的采样参数
+
+	// Error 及以上级别的日志不采样
+	ExemptErrors bool `config:"exempt_errors"`
+
+	// 输出被丢弃日志数量汇总的周期，默认1m
+	SummaryInterval time.Duration `config:"summary_interval"`
+}
+
+// Clone 复制配置
+func (config *SamplingConfig) Clone() *SamplingConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	if config.Levels != nil {
+		ret.Levels = make(map[log_level.Level]LevelSampling, len(config.Levels))
+		for level, s := range config.Levels {
+			ret.Levels[level] = s
+		}
+	}
+	return &ret
+}
+
+// For 返回指定级别的采样参数，ok 为 false 表示该级别不采样
+func (config *SamplingConfig) For(level log_level.Level) (s LevelSampling, ok bool) {
+	if config == nil {
+		return s, false
+	}
+	if config.ExemptErrors && level >= log_level.ErrorLevel {
+		return s, false
+	}
+
+	s, ok = config.Levels[level]
+	if !ok {
+		s = config.LevelSampling
+	}
+	return s, s.First > 0
+}
+
+// Validate 检查采样参数
+func (config *SamplingConfig) Validate() error {
+	if config == nil {
+		return nil
+	}
+
+	if config.Interval < 0 || config.SummaryInterval < 0 {
+		return fmt.Errorf("sampling interval must not be negative")
+	}
+	if config.First < 0 || config.Thereafter < 0 {
+		return fmt.Errorf("sampling first and thereafter must not be negative")
+	}
+	for level, s := range config.Levels {
+		if s.First < 0 || s.Thereafter < 0 {
+			return fmt.Errorf("sampling %s: first and thereafter must not be negative", level)
+		}
+	}
+	return nil
+}
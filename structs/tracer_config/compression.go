@@ -6,3 +6,14 @@ const (
 	No Compression = iota
 	Gzip
 )
+
+func (c Compression) String() string {
+	switch c {
+	case No:
+		return "none"
+	case Gzip:
+		return "gzip"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,187 @@
+package tracer_config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 支持的 OTEL_* 标准环境变量
+const (
+	EnvSDKDisabled        = "OTEL_SDK_DISABLED"
+	EnvServiceName        = "OTEL_SERVICE_NAME"
+	EnvEndpoint           = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvTracesEndpoint     = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	EnvProtocol           = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	EnvTracesProtocol     = "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+	EnvHeaders            = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvTracesHeaders      = "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	EnvCompression        = "OTEL_EXPORTER_OTLP_COMPRESSION"
+	EnvTracesCompression  = "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION"
+	EnvTracesSampler      = "OTEL_TRACES_SAMPLER"
+	EnvTracesSamplerArg   = "OTEL_TRACES_SAMPLER_ARG"
+	EnvPropagators        = "OTEL_PROPAGATORS"
+	defaultHTTPTracesPath = "/v1/traces"
+)
+
+// 支持的 OTEL_PROPAGATORS 取值
+var supportedPropagators = map[string]bool{
+	"tracecontext": true,
+	"baggage":      true,
+	"b3":           true,
+	"b3multi":      true,
+	"none":         true,
+}
+
+// ApplyEnv 使用 OTEL_* 标准环境变量覆盖配置，未设置的环境变量不影响原有配置
+// 按规范忽略无法解析或不支持的取值，返回对应的警告
+func (config *TracerConfig) ApplyEnv() (warnings []error) {
+	if config == nil {
+		return nil
+	}
+
+	if v, ok := lookupEnv(EnvSDKDisabled); ok {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("%s: %w, ignored", EnvSDKDisabled, err))
+		} else if disabled {
+			config.Enable = false
+		}
+	}
+
+	if key, v, ok := lookupEnvKey(EnvTracesProtocol, EnvProtocol); ok {
+		switch v {
+		case "http/protobuf":
+			config.Protocol = HTTPProtobuf
+		case "grpc":
+			config.Protocol = GRPC
+		default:
+			warnings = append(warnings, fmt.Errorf("%s: unsupported protocol %q, ignored", key, v))
+		}
+	}
+
+	if v, ok := lookupEnv(EnvTracesEndpoint); ok {
+		config.CollectorEndpoint = v
+	} else if v, ok := lookupEnv(EnvEndpoint); ok {
+		// 通用endpoint为基础地址，http协议需要追加traces路径
+		if config.Protocol == HTTPProtobuf {
+			v = strings.TrimRight(v, "/") + defaultHTTPTracesPath
+		}
+		config.CollectorEndpoint = v
+	}
+
+	for _, key := range []string{EnvHeaders, EnvTracesHeaders} {
+		v, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+		headers, err := parseHeaders(v)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("%s: %w, ignored", key, err))
+			continue
+		}
+		if config.Headers == nil {
+			config.Headers = make(map[string]string, len(headers))
+		}
+		for k, hv := range headers {
+			config.Headers[k] = hv
+		}
+	}
+
+	if key, v, ok := lookupEnvKey(EnvTracesCompression, EnvCompression); ok {
+		switch v {
+		case "gzip":
+			config.Compression = Gzip
+		case "none":
+			config.Compression = No
+		default:
+			warnings = append(warnings, fmt.Errorf("%s: unsupported compression %q, ignored", key, v))
+		}
+	}
+
+	samplerSet := false
+	if v, ok := lookupEnv(EnvTracesSampler); ok {
+		if sampler := Sampler(v); sampler.Valid() {
+			config.Sampler = sampler
+			samplerSet = true
+		} else {
+			warnings = append(warnings, fmt.Errorf("%s: unsupported sampler %q, ignored", EnvTracesSampler, v))
+		}
+	}
+
+	argSet := false
+	if v, ok := lookupEnv(EnvTracesSamplerArg); ok {
+		arg, err := strconv.ParseFloat(v, 64)
+		switch {
+		case err != nil:
+			warnings = append(warnings, fmt.Errorf("%s: %w, ignored", EnvTracesSamplerArg, err))
+		case arg < 0 || arg > 1:
+			warnings = append(warnings, fmt.Errorf("%s: %v out of range [0, 1], ignored", EnvTracesSamplerArg, arg))
+		default:
+			config.SamplerArg = arg
+			argSet = true
+		}
+	}
+	// 规范中比例采样未设置参数时默认为 1.0
+	if samplerSet && !argSet && config.Sampler.IsRatio() {
+		config.SamplerArg = 1
+	}
+
+	if v, ok := lookupEnv(EnvPropagators); ok {
+		var propagators []string
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if !supportedPropagators[p] {
+				warnings = append(warnings, fmt.Errorf("%s: unsupported propagator %q, ignored", EnvPropagators, p))
+				continue
+			}
+			propagators = append(propagators, p)
+		}
+		if len(propagators) > 0 {
+			config.Propagators = propagators
+		}
+	}
+
+	return warnings
+}
+
+// lookupEnv 按顺序返回第一个非空的环境变量
+func lookupEnv(keys ...string) (string, bool) {
+	_, v, ok := lookupEnvKey(keys...)
+	return v, ok
+}
+
+// lookupEnvKey 同 lookupEnv，同时返回实际读取的环境变量名，用于警告信息
+func lookupEnvKey(keys ...string) (key, value string, ok bool) {
+	for _, key = range keys {
+		if value = strings.TrimSpace(os.Getenv(key)); value != "" {
+			return key, value, true
+		}
+	}
+	return "", "", false
+}
+
+// parseHeaders 解析 key1=value1,key2=value2 格式的header，value 支持百分号编码
+func parseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header %q", pair)
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q: %w", pair, err)
+		}
+		headers[key] = value
+	}
+	return headers, nil
+}
@@ -0,0 +1,77 @@
+package tracer_config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnvIgnoresUnsupportedValues(t *testing.T) {
+	t.Setenv(EnvTracesSampler, "xray")
+	t.Setenv(EnvPropagators, "tracecontext,jaeger,baggage,ottrace")
+	t.Setenv(EnvProtocol, "thrift")
+	t.Setenv(EnvCompression, "zstd")
+
+	config := DefaultTracerConfig.Clone()
+	config.Sampler = AlwaysOn
+	warnings := config.ApplyEnv()
+
+	if len(warnings) != 5 {
+		t.Fatalf("got %d warnings, want 5: %v", len(warnings), warnings)
+	}
+	if config.Sampler != AlwaysOn {
+		t.Errorf("sampler: got %q, want %q", config.Sampler, AlwaysOn)
+	}
+	if want := []string{"tracecontext", "baggage"}; !reflect.DeepEqual(config.Propagators, want) {
+		t.Errorf("propagators: got %v, want %v", config.Propagators, want)
+	}
+	if config.Protocol != DefaultTracerConfig.Protocol || config.Compression != DefaultTracerConfig.Compression {
+		t.Errorf("protocol/compression changed: %v/%v", config.Protocol, config.Compression)
+	}
+}
+
+// 警告中的变量名为实际读取的变量，traces 专用变量优先于通用变量
+func TestApplyEnvWarningNamesVariable(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{EnvTracesProtocol, "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL: unsupported protocol"},
+		{EnvProtocol, "OTEL_EXPORTER_OTLP_PROTOCOL: unsupported protocol"},
+		{EnvTracesCompression, "OTEL_EXPORTER_OTLP_TRACES_COMPRESSION: unsupported compression"},
+		{EnvCompression, "OTEL_EXPORTER_OTLP_COMPRESSION: unsupported compression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, "invalid")
+
+			warnings := DefaultTracerConfig.Clone().ApplyEnv()
+			if len(warnings) != 1 || !strings.HasPrefix(warnings[0].Error(), tt.want) {
+				t.Errorf("got %v, want %s", warnings, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvRatioSamplerDefaultArg(t *testing.T) {
+	for _, sampler := range []string{"traceidratio", "parentbased_traceidratio"} {
+		t.Run(sampler, func(t *testing.T) {
+			t.Setenv(EnvTracesSampler, sampler)
+
+			config := DefaultTracerConfig.Clone()
+			if warnings := config.ApplyEnv(); len(warnings) != 0 {
+				t.Fatalf("unexpected warnings: %v", warnings)
+			}
+			if config.SamplerArg != 1 {
+				t.Errorf("sampler arg: got %v, want 1", config.SamplerArg)
+			}
+
+			t.Setenv(EnvTracesSamplerArg, "0.25")
+			config = DefaultTracerConfig.Clone()
+			config.ApplyEnv()
+			if config.SamplerArg != 0.25 {
+				t.Errorf("sampler arg: got %v, want 0.25", config.SamplerArg)
+			}
+		})
+	}
+}
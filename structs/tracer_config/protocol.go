@@ -0,0 +1,19 @@
+package tracer_config
+
+type Protocol uint8
+
+const (
+	HTTPProtobuf Protocol = iota
+	GRPC
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case HTTPProtobuf:
+		return "http/protobuf"
+	case GRPC:
+		return "grpc"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,29 @@
+package tracer_config
+
+// Sampler 采样策略，取值与 OTEL_TRACES_SAMPLER 保持一致
+type Sampler string
+
+const (
+	AlwaysOn                Sampler = "always_on"
+	AlwaysOff               Sampler = "always_off"
+	TraceIDRatio            Sampler = "traceidratio"
+	ParentBasedAlwaysOn     Sampler = "parentbased_always_on"
+	ParentBasedAlwaysOff    Sampler = "parentbased_always_off"
+	ParentBasedTraceIDRatio Sampler = "parentbased_traceidratio"
+)
+
+// Valid 判断采样策略是否合法，空值表示使用默认的 always_on
+func (s Sampler) Valid() bool {
+	switch s {
+	case "", AlwaysOn, AlwaysOff, TraceIDRatio,
+		ParentBasedAlwaysOn, ParentBasedAlwaysOff, ParentBasedTraceIDRatio:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRatio 是否为按比例采样，需要 SamplerArg 参数
+func (s Sampler) IsRatio() bool {
+	return s == TraceIDRatio || s == ParentBasedTraceIDRatio
+}
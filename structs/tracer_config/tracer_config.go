@@ -9,20 +9,90 @@ import (
 var DefaultTracerConfig = TracerConfig{
 	Enable:            true,
 	Compression:       No,
-	ServiceName:       os.Getenv("SERVICE_NAME"),
 	CollectorEndpoint: os.Getenv("OTEL_COLLECTOR_DNS"),
 	ContextHandlers:   make(map[string]ContextHandler),
 }
 
 type TracerConfig struct {
-	Enable bool // 开启Tracing功能
+	Enable bool `config:"enable"` // 开启Tracing功能
 
-	ServiceName       string
-	Level             log_level.Level
-	Compression       Compression
-	CollectorEndpoint string // CollectorEndpoint
+	ServiceName       string            `config:"service_name"` // 单独指定span资源中的服务名，空时使用 LogConfig.ServiceName
+	Level             log_level.Level   `config:"level"`
+	Compression       Compression       `config:"compression"`
+	CollectorEndpoint string            `config:"collector_endpoint"` // CollectorEndpoint：host:port 或完整的URL
+	Protocol          Protocol          `config:"protocol"`
+	Headers           map[string]string `config:"headers,secret"` // 导出时附带的header，如鉴权信息
+
+	Sampler     Sampler  `config:"sampler"`     // 采样策略，默认 always_on
+	SamplerArg  float64  `config:"sampler_arg"` // 采样参数，traceidratio 类采样器的采样比例
+	Propagators []string `config:"propagators"` // 传播协议，默认 tracecontext,baggage
 
 	ContextHandlers map[string]ContextHandler
+
+	// 请求未携带trace信息时，是否生成请求级别的trace_id用于日志关联，仅在未开启导出时生效
+	GenerateTraceID bool `config:"generate_trace_id"`
+
+	// baggage日志记录：AllowList非空时仅记录其中的key，DenyList中的key不记录
+	BaggageAllowList []string `config:"baggage_allow_list"`
+	BaggageDenyList  []string `config:"baggage_deny_list"`
+}
+
+// Clone 复制配置，避免修改共享的默认配置
+func (config *TracerConfig) Clone() *TracerConfig {
+	if config == nil {
+		return nil
+	}
+
+	ret := *config
+	ret.ContextHandlers = make(map[string]ContextHandler, len(config.ContextHandlers))
+	for key, handler := range config.ContextHandlers {
+		ret.ContextHandlers[key] = handler
+	}
+	if config.Headers != nil {
+		ret.Headers = make(map[string]string, len(config.Headers))
+		for key, value := range config.Headers {
+			ret.Headers[key] = value
+		}
+	}
+	ret.Propagators = append([]string(nil), config.Propagators...)
+	return &ret
+}
+
+// ExporterEqual 判断两份配置的导出相关设置是否一致，一致时可以复用已初始化的导出器
+func (config *TracerConfig) ExporterEqual(other *TracerConfig) bool {
+	if config == nil || other == nil {
+		return config == other
+	}
+
+	if config.Enable != other.Enable ||
+		config.ServiceName != other.ServiceName ||
+		config.Level != other.Level ||
+		config.Compression != other.Compression ||
+		config.CollectorEndpoint != other.CollectorEndpoint ||
+		config.Protocol != other.Protocol ||
+		config.Sampler != other.Sampler ||
+		config.SamplerArg != other.SamplerArg {
+		return false
+	}
+
+	if len(config.Headers) != len(other.Headers) {
+		return false
+	}
+	for key, value := range config.Headers {
+		if v, ok := other.Headers[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	if len(config.Propagators) != len(other.Propagators) {
+		return false
+	}
+	for i := range config.Propagators {
+		if config.Propagators[i] != other.Propagators[i] {
+			return false
+		}
+	}
+	return true
 }
 
 func (config *TracerConfig) FixDefault() {
@@ -52,5 +122,42 @@ func (config *TracerConfig) Validate() bool {
 		return false
 	}
 
+	if config.Protocol > GRPC {
+		return false
+	}
+
+	if !config.Sampler.Valid() {
+		return false
+	}
+
+	for _, p := range config.Propagators {
+		if !supportedPropagators[p] {
+			return false
+		}
+	}
+
 	return true
 }
+
+// LogBaggage 判断baggage中的key是否需要自动记录到日志
+func (config *TracerConfig) LogBaggage(key string) bool {
+	if config == nil {
+		return true
+	}
+
+	for _, k := range config.BaggageDenyList {
+		if k == key {
+			return false
+		}
+	}
+
+	if len(config.BaggageAllowList) == 0 {
+		return true
+	}
+	for _, k := range config.BaggageAllowList {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}